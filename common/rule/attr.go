@@ -12,6 +12,11 @@ func AttrBool(r *rule.Rule, attr string) bool {
 		if i, isIdent := v.(*build.Ident); isIdent {
 			return i.Name == "True"
 		}
+
+		// Attributes of generated rules such as SetAttr(attr, true)
+		if l, isLiteral := v.(*build.LiteralExpr); isLiteral {
+			return l.Token == "True"
+		}
 	}
 
 	return false
@@ -98,3 +98,36 @@ func collectSourceRegularSubFiles(wg *sync.WaitGroup, base, rel string, d walk.D
 		}(sdRel)
 	}
 }
+
+// Return the regular files within the directory, and within all subdirectories if
+// recursive, relative to the directory.
+//
+// Unlike GetSourceRegularFiles subdirectories containing BUILD files are included.
+func GetAllRegularFiles(rel string, recursive bool) ([]string, error) {
+	d, err := walk.GetDirInfo(rel)
+	if err != nil {
+		return nil, err
+	}
+
+	files := slices.Clone(d.RegularFiles)
+
+	if recursive {
+		for _, sd := range d.Subdirs {
+			sdRel := sd
+			if rel != "" {
+				sdRel = rel + "/" + sd
+			}
+
+			sdFiles, err := GetAllRegularFiles(sdRel, true)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range sdFiles {
+				files = append(files, sd+"/"+f)
+			}
+		}
+	}
+
+	slices.Sort(files)
+	return files, nil
+}
@@ -0,0 +1,172 @@
+package gazelle
+
+import (
+	"slices"
+
+	BazelLog "github.com/aspect-build/aspect-gazelle/common/logger"
+	node "github.com/aspect-build/aspect-gazelle/language/js/node"
+	"github.com/aspect-build/aspect-gazelle/language/js/parser"
+	"github.com/bazelbuild/bazel-gazelle/config"
+	"github.com/bazelbuild/bazel-gazelle/label"
+	"github.com/bazelbuild/bazel-gazelle/resolve"
+)
+
+// Barrel files such as an index.ts re-exporting other modules are followed to the
+// files defining the names imported from the barrel, so the importing target only
+// depends on the targets of the defining files instead of all modules of the barrel.
+
+// The names exported by a source file.
+type moduleExports struct {
+	// The workspace path of the source file
+	file string
+
+	// The names declared and exported by the file
+	exports []string
+
+	// The names re-exported from other modules
+	reExports []parser.ReExport
+
+	// If the file is the only source of its target
+	isOwnTarget bool
+}
+
+// Record the exports of a source file under each path the file can be imported as.
+func (ts *typeScriptLang) addModuleExports(sourcePath string, exports []string, reExports []parser.ReExport, isOwnTarget bool) {
+	m := &moduleExports{
+		file:        sourcePath,
+		exports:     exports,
+		reExports:   reExports,
+		isOwnTarget: isOwnTarget,
+	}
+
+	for _, importPath := range toImportPaths(sourcePath) {
+		ts.moduleExports[importPath] = m
+	}
+}
+
+// Find the exports of the module imported by the import statement, including
+// modules imported via tsconfig paths or the package.json exports of workspace packages.
+func (ts *typeScriptLang) findImportedModuleExports(c *config.Config, from label.Label, impStm ImportStatement) *moduleExports {
+	if m := ts.moduleExports[impStm.Imp]; m != nil {
+		return m
+	}
+
+	for _, p := range ts.tsconfig.ExpandPaths(impStm.SourcePath, impStm.ImportPath) {
+		if m := ts.moduleExports[toImportSpecPath(impStm.SourcePath, p)]; m != nil {
+			return m
+		}
+	}
+
+	if pkg, subFile := node.ParseImportPath(impStm.Imp); pkg != "" {
+		for _, p := range ts.expandPackageExports(c, from, pkg, subFile) {
+			if m := ts.moduleExports[p]; m != nil {
+				return m
+			}
+			if m := ts.moduleExports[toJsFile(p)]; m != nil {
+				return m
+			}
+		}
+	}
+
+	return nil
+}
+
+// Find the file defining an exported name by following the re-exports of the module.
+//
+// Returns the module itself if the name is re-exported from a module with unknown
+// exports such as a package, or nil if the name is not exported by the module.
+func (ts *typeScriptLang) findExportingModule(m *moduleExports, name string, visited map[string]bool) *moduleExports {
+	if visited[m.file] {
+		return nil
+	}
+	visited[m.file] = true
+
+	if slices.Contains(m.exports, name) {
+		return m
+	}
+
+	// Explicit re-exports such as `export { a } from "./a"` or `export * as a from "./a"`
+	for _, re := range m.reExports {
+		if re.Name != name {
+			continue
+		}
+
+		reExported := ts.moduleExports[toImportSpecPath(m.file, re.From)]
+		if reExported == nil {
+			return m
+		}
+		if re.Imported == "*" {
+			return reExported
+		}
+		if exporting := ts.findExportingModule(reExported, re.Imported, visited); exporting != nil {
+			return exporting
+		}
+		return m
+	}
+
+	// Names of `export * from "./a"`, which never includes the default export
+	if name != "default" {
+		for _, re := range m.reExports {
+			if re.Name != "*" {
+				continue
+			}
+
+			if reExported := ts.moduleExports[toImportSpecPath(m.file, re.From)]; reExported != nil {
+				if exporting := ts.findExportingModule(reExported, name, visited); exporting != nil {
+					return exporting
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Narrow an import of names from a barrel file to the target of the barrel and the
+// targets of the files defining the imported names.
+//
+// The target of the barrel is always kept so the imported module itself can be
+// resolved, only barrels that are the only source of their target are narrowed.
+//
+// Returns nil if the import can not be narrowed and should depend on the target of
+// the imported module.
+func (ts *typeScriptLang) narrowBarrelImport(c *config.Config, ix *resolve.RuleIndex, from label.Label, impStm ImportStatement, dep *label.Label) []*label.Label {
+	cfg := c.Exts[LanguageName].(*JsGazelleConfig)
+	if !cfg.narrowBarrelImports || len(impStm.Names) == 0 {
+		return nil
+	}
+
+	barrel := ts.findImportedModuleExports(c, from, impStm)
+	if barrel == nil || len(barrel.reExports) == 0 || !barrel.isOwnTarget {
+		return nil
+	}
+
+	narrowed := make([]*label.Label, 0, len(impStm.Names)+1)
+	narrowed = append(narrowed, dep)
+	for _, name := range impStm.Names {
+		exporting := ts.findExportingModule(barrel, name, map[string]bool{})
+		if exporting == nil {
+			BazelLog.Debugf("%q import of %q from %q not found, not narrowing", impStm.SourcePath, name, barrel.file)
+			return nil
+		}
+
+		if exporting == barrel {
+			continue
+		}
+
+		resolution, exportingDep, err := ts.resolveExpandedImport(c, ix, from, impStm, toImportPaths(exporting.file)[0])
+		if err != nil || resolution == Resolution_NotFound {
+			BazelLog.Debugf("%q import of %q from %q defined in unknown target of %q, not narrowing", impStm.SourcePath, name, barrel.file, exporting.file)
+			return nil
+		}
+
+		// Names defined by a file of the importing target need no dependency
+		if exportingDep != nil {
+			narrowed = append(narrowed, exportingDep)
+		}
+	}
+
+	BazelLog.Tracef("%q import of %v from %q narrowed to %v", impStm.SourcePath, impStm.Names, barrel.file, narrowed)
+
+	return narrowed
+}
@@ -3,9 +3,11 @@ package gazelle
 import (
 	"fmt"
 	"path"
+	"slices"
 	"strings"
 
 	common "github.com/aspect-build/aspect-gazelle/common"
+	node "github.com/aspect-build/aspect-gazelle/language/js/node"
 	"github.com/bazelbuild/bazel-gazelle/label"
 )
 
@@ -29,6 +31,8 @@ const (
 	Directive_TsconfigFile = "js_tsconfig_file"
 	// Ignore and do not generate a tsconfig related `ts_project` attribute
 	Directive_TypeScriptConfigIgnore = "js_tsconfig_ignore"
+	// En/disable excluding sources outside the tsconfig include/exclude/files
+	Directive_TsconfigCompilationSet = "js_tsconfig_compilation_set"
 	// Directive_IgnoreImports represents the directive that controls the
 	// ignored dependencies from the generated targets.
 	// Sub-packages extend this value.
@@ -48,6 +52,10 @@ const (
 	Directive_NpmPackageNameConvention = "js_npm_package_target_name"
 	// The target name for the ts_proto_library() rules. Interpolates {proto_library} with the name of the proto_library target.
 	Directive_ProtoNamingConvention = "js_proto_naming_convention"
+	// The target name for the js_grpc_web_library() rules. Interpolates {proto_library} with the name of the proto_library target.
+	Directive_GrpcWebNamingConvention = "js_grpc_web_naming_convention"
+	// The flavors of code generated for proto_library() targets such as message types and service stubs.
+	Directive_ProtoFlavor = "js_proto_flavor"
 	// Directive_TestsNamingConvention represents the directive that controls the ts_project test
 	// naming convention. See js_project_naming_convention for more info on
 	// the package name interpolation.
@@ -58,6 +66,41 @@ const (
 	Directive_LibraryFiles = "js_files"
 	// The glob for test files.
 	Directive_TestFiles = "js_test_files"
+	// The glob for entry point files to generate js_binary() targets for.
+	Directive_BinaryFiles = "js_binary_files"
+	// The package.json "exports" and "imports" conditions used when resolving imports.
+	Directive_PackageConditions = "js_package_conditions"
+	// The file extensions of assets such as stylesheets and images that may be imported.
+	Directive_AssetExtensions = "js_asset_extensions"
+	// The import map or deno.json used to map import specifiers.
+	Directive_ImportMap = "js_import_map"
+	// Directive_ValidateUrlImports controls whether URL imports such as "https://..."
+	// not resolved by a js_resolve directive are reported.
+	Directive_ValidateUrlImports = "js_validate_url_imports"
+	// Directive_ValidateNpmDependencies controls whether phantom and unused npm
+	// dependencies of pnpm projects are reported.
+	Directive_ValidateNpmDependencies = "js_validate_npm_dependencies"
+	// Directive_IgnoreUnusedNpmDependencies specifies npm dependencies not reported as
+	// unused, such as tools not imported by any source.
+	Directive_IgnoreUnusedNpmDependencies = "js_ignore_unused_npm_dependencies"
+	// Directive_ValidateImportCycles controls whether import cycles between
+	// generated targets are reported.
+	Directive_ValidateImportCycles = "js_validate_import_cycles"
+	// Directive_ValidateNpmPackageFiles controls whether files referenced by the
+	// package.json of npm packages and not produced by any target are reported.
+	Directive_ValidateNpmPackageFiles = "js_validate_npm_package_files"
+	// Directive_TestRunner controls whether tests are run with the test runner of the
+	// nearest jest, vitest or mocha configuration file.
+	Directive_TestRunner = "js_test_runner"
+	// Directive_NarrowBarrelImports controls whether named imports of barrel files
+	// re-exporting other modules depend on the targets defining the imported names.
+	Directive_NarrowBarrelImports = "js_narrow_barrel_imports"
+	// The platform the code runs on, determining which builtin modules may be imported.
+	Directive_Platform = "js_platform"
+	// The node version determining the available node builtin modules.
+	Directive_NodeVersion = "js_node_version"
+	// The granularity of the generated source targets, a target per package or per source file.
+	Directive_Granularity = "js_granularity"
 
 	// TODO(deprecated): remove - replaced with js_files [group]
 	Directive_CustomTargetFiles = "js_custom_files"
@@ -85,6 +128,9 @@ const (
 	ProtoNameVar            = "{proto_library}"
 	DefaultProtoLibraryName = ProtoNameVar + "_ts"
 
+	// The name for js_grpc_web_library rules of the grpc_web proto flavor
+	DefaultGrpcWebLibraryName = ProtoNameVar + "_grpc_web"
+
 	// The suffix added to the end of a target being wrapped in a package.
 	PackageSrcSuffix = "_lib"
 
@@ -131,6 +177,14 @@ var (
 
 	// Array of default typescript source file extensions
 	defaultTypescriptFileExtensionsArray = []string{"ts", "tsx", "mts", "cts"}
+
+	// The default file extensions of importable assets
+	DefaultAssetExtensions = []string{
+		".css", ".scss", ".sass", ".less",
+		".svg", ".png", ".jpg", ".jpeg", ".gif", ".webp", ".avif", ".ico", ".bmp",
+		".woff", ".woff2", ".ttf", ".otf", ".eot",
+		".wasm",
+	}
 )
 
 // ValidationMode represents what should happen when validation errors are found.
@@ -145,6 +199,42 @@ const (
 	ValidationOff
 )
 
+// Platform represents the platform code runs on.
+type Platform string
+
+const (
+	// PlatformNode code may import the node builtin modules.
+	PlatformNode Platform = "node"
+	// PlatformBrowser code may not import node builtin modules.
+	PlatformBrowser Platform = "browser"
+	// PlatformNeutral code has no builtin modules, imports of node builtins must be resolved
+	// as any other import such as to a polyfill package.
+	PlatformNeutral Platform = "neutral"
+)
+
+// Granularity represents the granularity of the generated source targets.
+type Granularity string
+
+const (
+	// GranularityPackage generates a target per target group of a package.
+	GranularityPackage Granularity = "package"
+	// GranularityFile generates a target per source file, merging files with
+	// cyclic imports into a single target.
+	GranularityFile Granularity = "file"
+)
+
+// ProtoFlavor represents a flavor of code generated for proto_library targets.
+type ProtoFlavor string
+
+const (
+	// ProtoFlavorEs generates protobuf-es message types with ts_proto_library.
+	ProtoFlavorEs ProtoFlavor = "es"
+	// ProtoFlavorConnect generates connect-es service stubs with ts_proto_library(gen_connect_es).
+	ProtoFlavorConnect ProtoFlavor = "connect"
+	// ProtoFlavorGrpcWeb generates grpc-web service stubs with js_grpc_web_library.
+	ProtoFlavorGrpcWeb ProtoFlavor = "grpc_web"
+)
+
 type PackageTargetKind string
 
 const (
@@ -168,6 +258,7 @@ type JsGazelleConfig struct {
 
 	protoGenerationEnabled    bool
 	tsconfigGenerationEnabled bool
+	tsconfigCompilationSet    bool
 	packageGenerationEnabled  NpmPackageMode
 
 	pnpmLockRel  string
@@ -181,12 +272,54 @@ type JsGazelleConfig struct {
 	resolves                 []jsResolve
 	validateImportStatements ValidationMode
 	targets                  []*TargetGroup
+	binaryFiles              []string
+	packageConditions        []string
+	assetExtensions          []string
+
+	// The nearest test runner configuration such as a jest.config.js
+	testRunner        *testRunnerConfig
+	testRunnerEnabled bool
+
+	// The import map used to map import specifiers and the workspace path of
+	// the file declaring it, loaded once when configured
+	importMap     *ImportMap
+	importMapFile string
+
+	// The validation of URL imports, defaults to the validateImportStatements
+	validateUrlImports *ValidationMode
+
+	// The validation of npm dependencies declared by pnpm projects
+	validateNpmDependencies ValidationMode
+
+	// The npm dependencies not reported as unused
+	ignoredUnusedNpmDependencies []common.GlobExpr
+
+	// The validation of import cycles between targets
+	validateImportCycles ValidationMode
+
+	// The validation of files referenced by the package.json of npm packages
+	validateNpmPackageFiles ValidationMode
+
+	// If named imports of barrel files depend on the targets defining the names
+	narrowBarrelImports bool
+
+	// The platform and node version determining the builtin modules
+	platform    Platform
+	nodeVersion node.NodeVersion
+
+	// The granularity of the generated source targets
+	granularity Granularity
 
 	// Generated rule names
 	npmLinkAllTargetName       string
 	targetNamingOverrides      map[string]string
 	npmPackageNamingConvention string
 	tsProtoLibraryName         string
+	grpcWebLibraryName         string
+
+	// The flavors of code generated for proto_library targets, nil if not configured
+	// by a js_proto_flavor directive which generates the "es" flavor
+	protoFlavors []ProtoFlavor
 }
 
 // New creates a new JsGazelleConfig.
@@ -196,6 +329,7 @@ func newRootConfig() *JsGazelleConfig {
 		generationEnabled:          true,
 		protoGenerationEnabled:     true,
 		tsconfigGenerationEnabled:  true,
+		tsconfigCompilationSet:     false,
 		packageGenerationEnabled:   NpmPackageReferencedMode,
 		packageTargetKind:          PackageTargetKind_Package,
 		pnpmLockRel:                "",
@@ -206,11 +340,22 @@ func newRootConfig() *JsGazelleConfig {
 		tsconfigIgnoredProps:       []string{},
 		resolves:                   []jsResolve{},
 		validateImportStatements:   ValidationError,
+		validateNpmDependencies:    ValidationOff,
+		validateImportCycles:       ValidationOff,
+		validateNpmPackageFiles:    ValidationOff,
+		testRunnerEnabled:          true,
 		npmLinkAllTargetName:       DefaultNpmLinkAllTargetName,
 		npmPackageNamingConvention: DefaultNpmPackageTargetName,
 		targetNamingOverrides:      make(map[string]string),
 		tsProtoLibraryName:         DefaultProtoLibraryName,
+		grpcWebLibraryName:         DefaultGrpcWebLibraryName,
+		protoFlavors:               nil,
 		targets:                    DefaultSourceGlobs[:],
+		binaryFiles:                []string{},
+		packageConditions:          node.DefaultConditions,
+		assetExtensions:            DefaultAssetExtensions,
+		platform:                   PlatformNode,
+		granularity:                GranularityPackage,
 	}
 }
 
@@ -236,6 +381,7 @@ func (c *JsGazelleConfig) NewChild(childPath string) *JsGazelleConfig {
 	cCopy.rel = childPath
 	cCopy.parent = c
 	cCopy.ignoreDependencies = []common.GlobExpr{}
+	cCopy.ignoredUnusedNpmDependencies = []common.GlobExpr{}
 	cCopy.resolves = []jsResolve{}
 
 	// Copy the targets, any modifications will be local.
@@ -244,6 +390,9 @@ func (c *JsGazelleConfig) NewChild(childPath string) *JsGazelleConfig {
 		cCopy.targets = append(cCopy.targets, target.newChild())
 	}
 
+	// Copy the binary globs, any additions will be local.
+	cCopy.binaryFiles = slices.Clone(c.binaryFiles)
+
 	// Copy the overrides, any modifications will be local.
 	cCopy.targetNamingOverrides = make(map[string]string, len(c.targetNamingOverrides))
 	for k, v := range c.targetNamingOverrides {
@@ -297,6 +446,15 @@ func (c *JsGazelleConfig) GetTsConfigGenerationEnabled() bool {
 	return c.tsconfigGenerationEnabled
 }
 
+func (c *JsGazelleConfig) SetTsConfigCompilationSetEnabled(enabled bool) {
+	c.tsconfigCompilationSet = enabled
+}
+
+// If sources outside the tsconfig compilation set are excluded from source targets.
+func (c *JsGazelleConfig) GetTsConfigCompilationSetEnabled() bool {
+	return c.tsconfigCompilationSet
+}
+
 func (c *JsGazelleConfig) SetProtoGenerationEnabled(enabled bool) {
 	c.protoGenerationEnabled = enabled
 }
@@ -328,6 +486,30 @@ func (c *JsGazelleConfig) PnpmLockDir() string {
 	return c.pnpmLockDir
 }
 
+// Set the package.json conditions used when resolving package "exports" and "imports".
+func (c *JsGazelleConfig) SetPackageConditions(conditions []string) {
+	c.packageConditions = conditions
+}
+func (c *JsGazelleConfig) PackageConditions() []string {
+	return c.packageConditions
+}
+
+// Set the file extensions of assets that may be imported from sources.
+func (c *JsGazelleConfig) SetAssetExtensions(extensions []string) {
+	c.assetExtensions = make([]string, 0, len(extensions))
+	for _, ext := range extensions {
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		c.assetExtensions = append(c.assetExtensions, strings.ToLower(ext))
+	}
+}
+
+// If the file is an asset such as a stylesheet or image that may be imported from sources.
+func (c *JsGazelleConfig) IsAssetFile(f string) bool {
+	return slices.Contains(c.assetExtensions, strings.ToLower(path.Ext(f)))
+}
+
 // Set the tsconfig.json file name
 func (c *JsGazelleConfig) SetTsconfigFile(tsconfigName string) {
 	c.tsconfigName = path.Clean(tsconfigName)
@@ -426,6 +608,106 @@ func (c *JsGazelleConfig) ValidateImportStatements() ValidationMode {
 	return c.validateImportStatements
 }
 
+// SetValidateUrlImports sets the ValidationMode for URL imports not resolved
+// by a js_resolve directive.
+func (c *JsGazelleConfig) SetValidateUrlImports(mode ValidationMode) {
+	c.validateUrlImports = &mode
+}
+
+// ValidateUrlImports returns the ValidationMode for URL imports, defaulting to the
+// ValidationMode of all import statements.
+func (c *JsGazelleConfig) ValidateUrlImports() ValidationMode {
+	if c.validateUrlImports != nil {
+		return *c.validateUrlImports
+	}
+	return c.validateImportStatements
+}
+
+// SetTestRunnerEnabled sets whether tests are run with the detected test runner.
+func (c *JsGazelleConfig) SetTestRunnerEnabled(enabled bool) {
+	c.testRunnerEnabled = enabled
+}
+
+// The test runner configuration running the tests, nil if tests are js_test targets.
+func (c *JsGazelleConfig) getTestRunner() *testRunnerConfig {
+	if !c.testRunnerEnabled {
+		return nil
+	}
+	return c.testRunner
+}
+
+// SetNarrowBarrelImports sets whether named imports of barrel files such as an
+// index.ts re-exporting other modules depend on the targets defining the names.
+func (c *JsGazelleConfig) SetNarrowBarrelImports(enabled bool) {
+	c.narrowBarrelImports = enabled
+}
+
+// SetPlatform sets the platform the code runs on.
+func (c *JsGazelleConfig) SetPlatform(platform Platform) {
+	c.platform = platform
+}
+
+// SetNodeVersion sets the node version determining the available node builtin modules.
+func (c *JsGazelleConfig) SetNodeVersion(version node.NodeVersion) {
+	c.nodeVersion = version
+}
+
+// IsNodeBuiltinImport returns whether the import is a builtin module of the
+// configured platform and node version.
+func (c *JsGazelleConfig) IsNodeBuiltinImport(imprt string) bool {
+	return c.platform == PlatformNode && node.IsNodeBuiltinImport(imprt, c.nodeVersion)
+}
+
+// SetGranularity sets the granularity of the generated source targets.
+func (c *JsGazelleConfig) SetGranularity(granularity Granularity) {
+	c.granularity = granularity
+}
+
+// SetValidateNpmDependencies sets the ValidationMode for phantom and unused
+// npm dependencies of pnpm projects.
+func (c *JsGazelleConfig) SetValidateNpmDependencies(mode ValidationMode) {
+	c.validateNpmDependencies = mode
+}
+
+// ValidateNpmDependencies returns the ValidationMode for phantom and unused
+// npm dependencies, defaulting to off.
+func (c *JsGazelleConfig) ValidateNpmDependencies() ValidationMode {
+	return c.validateNpmDependencies
+}
+
+// AddIgnoredUnusedNpmDependency adds a glob of npm dependencies not reported as
+// unused in the package and subpackages.
+func (c *JsGazelleConfig) AddIgnoredUnusedNpmDependency(pkgGlob string) error {
+	ge, err := common.ParseGlobExpression(pkgGlob)
+	if err != nil {
+		return err
+	}
+
+	c.ignoredUnusedNpmDependencies = append(c.ignoredUnusedNpmDependencies, ge)
+	return nil
+}
+
+// IsUnusedNpmDependencyIgnored returns whether the npm dependency is not reported
+// as unused in the package or one of the parent packages.
+func (c *JsGazelleConfig) IsUnusedNpmDependencyIgnored(pkg string) bool {
+	for config := c; config != nil; config = config.parent {
+		for _, glob := range config.ignoredUnusedNpmDependencies {
+			if glob(pkg) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// SetImportMap sets the import map used to map import specifiers and the workspace
+// path of the file declaring it.
+func (c *JsGazelleConfig) SetImportMap(importMapFile string, importMap *ImportMap) {
+	c.importMap = importMap
+	c.importMapFile = importMapFile
+}
+
 // SetLibraryNamingConvention sets the ts_project target naming convention.
 func (c *JsGazelleConfig) SetLibraryNamingConvention(libraryNamingConvention string) {
 	c.targetNamingOverrides[DefaultLibraryName] = libraryNamingConvention
@@ -459,6 +741,32 @@ func (c *JsGazelleConfig) RenderTsProtoLibraryName(protoLibraryName string) stri
 	return strings.ReplaceAll(c.tsProtoLibraryName, ProtoNameVar, protoLibraryName)
 }
 
+func (c *JsGazelleConfig) SetGrpcWebLibraryNamingConvention(grpcWebLibraryName string) {
+	c.grpcWebLibraryName = grpcWebLibraryName
+}
+
+func (c *JsGazelleConfig) RenderGrpcWebLibraryName(protoLibraryName string) string {
+	return strings.ReplaceAll(c.grpcWebLibraryName, ProtoNameVar, protoLibraryName)
+}
+
+// SetProtoFlavors sets the flavors of code generated for proto_library targets.
+func (c *JsGazelleConfig) SetProtoFlavors(flavors []ProtoFlavor) {
+	c.protoFlavors = flavors
+}
+
+// HasProtoFlavor returns whether the flavor of code is generated for proto_library targets.
+func (c *JsGazelleConfig) HasProtoFlavor(flavor ProtoFlavor) bool {
+	if c.protoFlavors == nil {
+		return flavor == ProtoFlavorEs
+	}
+	return slices.Contains(c.protoFlavors, flavor)
+}
+
+// IsProtoFlavorConfigured returns whether the flavors were set by a js_proto_flavor directive.
+func (c *JsGazelleConfig) IsProtoFlavorConfigured() bool {
+	return c.protoFlavors != nil
+}
+
 func (c *JsGazelleConfig) RenderTsConfigName(tsconfigName string) string {
 	return strings.ReplaceAll(strings.TrimRight(path.Base(tsconfigName), ".json"), ".", "_")
 }
@@ -555,3 +863,50 @@ func (c *JsGazelleConfig) addTargetGlob(targetName, glob string, isTestOnly bool
 	})
 	return nil
 }
+
+// Add a glob of entry point files to generate js_binary() targets for.
+// Sub-packages extend this value.
+func (c *JsGazelleConfig) AddBinaryFiles(glob string) error {
+	if _, err := common.ParseGlobExpression(glob); err != nil {
+		return fmt.Errorf("Invalid binary glob: %v", glob)
+	}
+
+	c.binaryFiles = append(c.binaryFiles, glob)
+	return nil
+}
+
+// Determine if the passed file is a js_binary() entry point.
+func (c *JsGazelleConfig) IsBinaryFile(filePath string) bool {
+	for _, globExpr := range c.binaryFiles {
+		glob, _ := common.ParseGlobExpression(globExpr)
+
+		if glob(filePath) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetValidateImportCycles sets the ValidationMode for import cycles between targets.
+func (c *JsGazelleConfig) SetValidateImportCycles(mode ValidationMode) {
+	c.validateImportCycles = mode
+}
+
+// ValidateImportCycles returns the ValidationMode for import cycles between
+// targets, defaulting to off.
+func (c *JsGazelleConfig) ValidateImportCycles() ValidationMode {
+	return c.validateImportCycles
+}
+
+// SetValidateNpmPackageFiles sets the ValidationMode for files referenced by the
+// package.json of npm packages.
+func (c *JsGazelleConfig) SetValidateNpmPackageFiles(mode ValidationMode) {
+	c.validateNpmPackageFiles = mode
+}
+
+// ValidateNpmPackageFiles returns the ValidationMode for files referenced by the
+// package.json of npm packages, defaulting to off.
+func (c *JsGazelleConfig) ValidateNpmPackageFiles() ValidationMode {
+	return c.validateNpmPackageFiles
+}
@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"slices"
 	"strings"
 
 	common "github.com/aspect-build/aspect-gazelle/common"
 	BazelLog "github.com/aspect-build/aspect-gazelle/common/logger"
+	node "github.com/aspect-build/aspect-gazelle/language/js/node"
 	"github.com/bazelbuild/bazel-gazelle/config"
 	"github.com/bazelbuild/bazel-gazelle/label"
 	"github.com/bazelbuild/bazel-gazelle/rule"
@@ -45,6 +47,7 @@ func (ts *typeScriptLang) KnownDirectives() []string {
 		Directive_Lockfile,
 		Directive_TsconfigFile,
 		Directive_TypeScriptConfigIgnore,
+		Directive_TsconfigCompilationSet,
 		Directive_IgnoreImports,
 		Directive_Resolve,
 		Directive_ValidateImportStatements,
@@ -52,9 +55,25 @@ func (ts *typeScriptLang) KnownDirectives() []string {
 		Directive_TestsNamingConvention,
 		Directive_NpmPackageNameConvention,
 		Directive_ProtoNamingConvention,
+		Directive_GrpcWebNamingConvention,
+		Directive_ProtoFlavor,
 		Directive_PackageRuleKind,
 		Directive_LibraryFiles,
 		Directive_TestFiles,
+		Directive_BinaryFiles,
+		Directive_PackageConditions,
+		Directive_AssetExtensions,
+		Directive_ImportMap,
+		Directive_ValidateUrlImports,
+		Directive_ValidateNpmDependencies,
+		Directive_IgnoreUnusedNpmDependencies,
+		Directive_ValidateImportCycles,
+		Directive_ValidateNpmPackageFiles,
+		Directive_TestRunner,
+		Directive_NarrowBarrelImports,
+		Directive_Platform,
+		Directive_NodeVersion,
+		Directive_Granularity,
 
 		// TODO(deprecated): remove
 		Directive_CustomTargetFiles,
@@ -105,6 +124,27 @@ func (ts *typeScriptLang) readConfigurations(c *config.Config, rel string) {
 	if common.WalkHasPath(rel, config.tsconfigName) {
 		ts.tsconfig.SetTsConfigFile(c.RepoRoot, rel, config.tsconfigName)
 	}
+
+	// package.json
+	if common.WalkHasPath(rel, NpmPackageFilename) {
+		ts.packageJsonDirs[rel] = true
+	}
+
+	// test runner configuration such as jest.config.js
+	// Test runners with no standard rules must be mapped to a macro using map_kind.
+	if testRunner := findTestRunnerConfig(rel); testRunner != nil {
+		if ruleKind := testRunner.runner.ruleKind(); ruleKind == "" {
+			BazelLog.Warnf("Unknown test runner %q of config %q", testRunner.runner, testRunner.configFile)
+			config.testRunner = nil
+		} else if testRunner.runner.hasRuleLoad(c) {
+			config.testRunner = testRunner
+		} else {
+			if config.testRunnerEnabled {
+				fmt.Fprintf(os.Stderr, "Warning: %s config %q ignored, add a \"# gazelle:map_kind %s <macro> <bzl file>\" directive to generate %s rules\n", testRunner.runner, testRunner.configFile, ruleKind, ruleKind)
+			}
+			config.testRunner = nil
+		}
+	}
 }
 
 func (ts *typeScriptLang) readDirectives(c *config.Config, rel string, f *rule.File) {
@@ -157,6 +197,8 @@ func (ts *typeScriptLang) readDirectives(c *config.Config, rel string, f *rule.F
 			config.SetTsconfigFile(value)
 		case Directive_TypeScriptConfigIgnore:
 			config.AddIgnoredTsConfig(strings.TrimSpace(value))
+		case Directive_TsconfigCompilationSet:
+			config.SetTsConfigCompilationSetEnabled(common.ReadEnabled(d))
 		case Directive_IgnoreImports:
 			config.AddIgnoredImport(strings.TrimSpace(value))
 		case Directive_Resolve:
@@ -176,19 +218,106 @@ func (ts *typeScriptLang) readDirectives(c *config.Config, rel string, f *rule.F
 
 			config.AddResolve(strings.TrimSpace(globTarget[0]), &label)
 		case Directive_ValidateImportStatements:
-			switch value {
-			case "error":
-				config.SetValidateImportStatements(ValidationError)
-			case "warn":
-				config.SetValidateImportStatements(ValidationWarn)
-			case "off":
-				config.SetValidateImportStatements(ValidationOff)
-			default:
+			mode, ok := parseValidationMode(value)
+			if !ok {
 				common.MisconfiguredErrorf(c, "invalid value for directive %q: %s", Directive_ValidateImportStatements, d.Value)
 				return
 			}
+			config.SetValidateImportStatements(mode)
+		case Directive_ValidateUrlImports:
+			mode, ok := parseValidationMode(value)
+			if !ok {
+				common.MisconfiguredErrorf(c, "invalid value for directive %q: %s", Directive_ValidateUrlImports, d.Value)
+				return
+			}
+			config.SetValidateUrlImports(mode)
+		case Directive_TestRunner:
+			config.SetTestRunnerEnabled(common.ReadEnabled(d))
+		case Directive_NarrowBarrelImports:
+			config.SetNarrowBarrelImports(common.ReadEnabled(d))
+		case Directive_Platform:
+			switch Platform(value) {
+			case PlatformNode, PlatformBrowser, PlatformNeutral:
+				config.SetPlatform(Platform(value))
+			default:
+				common.MisconfiguredErrorf(c, "invalid value for directive %q: %s, expected node, browser or neutral", Directive_Platform, d.Value)
+				return
+			}
+		case Directive_NodeVersion:
+			version, ok := node.ParseNodeVersion(value)
+			if !ok {
+				common.MisconfiguredErrorf(c, "invalid value for directive %q: %s, expected a version such as 20 or 22.5", Directive_NodeVersion, d.Value)
+				return
+			}
+			config.SetNodeVersion(version)
+		case Directive_Granularity:
+			switch Granularity(value) {
+			case GranularityPackage, GranularityFile:
+				config.SetGranularity(Granularity(value))
+			default:
+				common.MisconfiguredErrorf(c, "invalid value for directive %q: %s, expected package or file", Directive_Granularity, d.Value)
+				return
+			}
+		case Directive_ValidateNpmDependencies:
+			mode, ok := parseValidationMode(value)
+			if !ok {
+				common.MisconfiguredErrorf(c, "invalid value for directive %q: %s", Directive_ValidateNpmDependencies, d.Value)
+				return
+			}
+			config.SetValidateNpmDependencies(mode)
+		case Directive_ValidateImportCycles:
+			mode, ok := parseValidationMode(value)
+			if !ok {
+				common.MisconfiguredErrorf(c, "invalid value for directive %q: %s", Directive_ValidateImportCycles, d.Value)
+				return
+			}
+			config.SetValidateImportCycles(mode)
+		case Directive_ValidateNpmPackageFiles:
+			mode, ok := parseValidationMode(value)
+			if !ok {
+				common.MisconfiguredErrorf(c, "invalid value for directive %q: %s", Directive_ValidateNpmPackageFiles, d.Value)
+				return
+			}
+			config.SetValidateNpmPackageFiles(mode)
+		case Directive_IgnoreUnusedNpmDependencies:
+			for _, pkgGlob := range strings.Fields(value) {
+				if err := config.AddIgnoredUnusedNpmDependency(pkgGlob); err != nil {
+					common.MisconfiguredErrorf(c, "invalid value for directive %q: %s: %v", Directive_IgnoreUnusedNpmDependencies, pkgGlob, err)
+					return
+				}
+			}
+		case Directive_ImportMap:
+			if value == "" {
+				common.MisconfiguredErrorf(c, "invalid value for directive %q: expected an import map or deno.json file", Directive_ImportMap)
+				return
+			}
+			importMap, importMapFile, err := loadImportMap(c, path.Join(rel, value))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to load import map %q: %v\n", importMapFile, err)
+			}
+			config.SetImportMap(importMapFile, importMap)
 		case Directive_ProtoNamingConvention:
 			config.SetTsProtoLibraryNamingConvention(value)
+		case Directive_GrpcWebNamingConvention:
+			config.SetGrpcWebLibraryNamingConvention(value)
+		case Directive_ProtoFlavor:
+			flavors := []ProtoFlavor{}
+			for _, flavor := range strings.Fields(value) {
+				switch ProtoFlavor(flavor) {
+				case ProtoFlavorEs, ProtoFlavorConnect, ProtoFlavorGrpcWeb:
+					if !slices.Contains(flavors, ProtoFlavor(flavor)) {
+						flavors = append(flavors, ProtoFlavor(flavor))
+					}
+				default:
+					common.MisconfiguredErrorf(c, "invalid value for directive %q: %s, expected es, connect or grpc_web", Directive_ProtoFlavor, flavor)
+					return
+				}
+			}
+			if len(flavors) == 0 {
+				common.MisconfiguredErrorf(c, "invalid value for directive %q: expected one or more of es, connect or grpc_web", Directive_ProtoFlavor)
+				return
+			}
+			config.SetProtoFlavors(flavors)
 		case Directive_LibraryNamingConvention:
 			config.SetLibraryNamingConvention(value)
 		case Directive_TestsNamingConvention:
@@ -225,6 +354,25 @@ func (ts *typeScriptLang) readDirectives(c *config.Config, rel string, f *rule.F
 			}
 
 			config.addTargetGlob(group, groupGlob, true)
+		case Directive_BinaryFiles:
+			if err := config.AddBinaryFiles(value); err != nil {
+				common.MisconfiguredErrorf(c, "invalid value for directive %q: %v", Directive_BinaryFiles, err)
+				return
+			}
+		case Directive_PackageConditions:
+			conditions := strings.Fields(value)
+			if len(conditions) == 0 {
+				common.MisconfiguredErrorf(c, "invalid value for directive %q: expected a list of conditions", Directive_PackageConditions)
+				return
+			}
+			config.SetPackageConditions(conditions)
+		case Directive_AssetExtensions:
+			extensions := strings.Fields(value)
+			if len(extensions) == 0 {
+				common.MisconfiguredErrorf(c, "invalid value for directive %q: expected a list of file extensions", Directive_AssetExtensions)
+				return
+			}
+			config.SetAssetExtensions(extensions)
 
 		// TODO: remove, deprecated
 		case Directive_CustomTargetFiles:
@@ -239,3 +387,16 @@ func (ts *typeScriptLang) readDirectives(c *config.Config, rel string, f *rule.F
 		}
 	}
 }
+
+func parseValidationMode(value string) (ValidationMode, bool) {
+	switch value {
+	case "error":
+		return ValidationError, true
+	case "warn":
+		return ValidationWarn, true
+	case "off":
+		return ValidationOff, true
+	default:
+		return ValidationError, false
+	}
+}
@@ -0,0 +1,215 @@
+package gazelle
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	BazelLog "github.com/aspect-build/aspect-gazelle/common/logger"
+	"github.com/bazelbuild/bazel-gazelle/config"
+	"github.com/bazelbuild/bazel-gazelle/label"
+)
+
+// Import cycles between targets are detected once the deps of all targets have been
+// resolved, instead of surfacing later as Bazel "cycle in dependency graph" errors.
+
+// A target of the import graph.
+type importGraphNode struct {
+	// The config of the target package
+	c *config.Config
+
+	// The imports of each dependency
+	deps map[string][]ImportStatement
+}
+
+// Add a target to the import graph if validating import cycles of the target.
+func (ts *typeScriptLang) addImportGraphNode(c *config.Config, from label.Label) {
+	cfg := c.Exts[LanguageName].(*JsGazelleConfig)
+	if cfg.ValidateImportCycles() == ValidationOff {
+		return
+	}
+
+	ts.importGraph[toImportGraphKey(from, from)] = &importGraphNode{
+		c:    c,
+		deps: make(map[string][]ImportStatement),
+	}
+}
+
+// Record the dependency of a target of the import graph on a target of the same repository.
+func (ts *typeScriptLang) addImportGraphEdge(from label.Label, dep *label.Label, imp ImportStatement) {
+	node := ts.importGraph[toImportGraphKey(from, from)]
+	if node == nil || dep == nil {
+		return
+	}
+
+	if dep.Repo != "" && dep.Repo != from.Repo {
+		return
+	}
+
+	depKey := toImportGraphKey(from, *dep)
+	isRecorded := slices.ContainsFunc(node.deps[depKey], func(recorded ImportStatement) bool {
+		return recorded.SourcePath == imp.SourcePath && recorded.ImportPath == imp.ImportPath
+	})
+	if !isRecorded {
+		node.deps[depKey] = append(node.deps[depKey], imp)
+	}
+}
+
+// The absolute label of a target within the repository of the `from` target.
+func toImportGraphKey(from, l label.Label) string {
+	abs := l.Abs(from.Repo, from.Pkg)
+	abs.Repo = ""
+	return abs.String()
+}
+
+// Report the import cycles between targets once all imports have been resolved.
+func (ts *typeScriptLang) reportImportCycles() {
+	graph := make(map[string][]string, len(ts.importGraph))
+	for key, node := range ts.importGraph {
+		graph[key] = make([]string, 0, len(node.deps))
+		for dep := range node.deps {
+			if ts.importGraph[dep] != nil {
+				graph[key] = append(graph[key], dep)
+			}
+		}
+	}
+
+	for _, component := range toStronglyConnectedComponents(graph) {
+		if len(component) < 2 {
+			continue
+		}
+
+		cycle := findImportCycle(graph, component)
+		start := ts.importGraph[cycle[0]]
+		cfg := start.c.Exts[LanguageName].(*JsGazelleConfig)
+
+		BazelLog.Debugf("import cycle between targets %v", cycle)
+
+		var cycleImports strings.Builder
+		for i, key := range cycle {
+			dep := cycle[(i+1)%len(cycle)]
+			fmt.Fprintf(&cycleImports, "\t%s -> %s\n", key, dep)
+
+			imports := ts.importGraph[key].deps[dep]
+			slices.SortFunc(imports, func(a, b ImportStatement) int {
+				return strings.Compare(a.SourcePath+"\x00"+a.ImportPath, b.SourcePath+"\x00"+b.ImportPath)
+			})
+			for _, imp := range imports {
+				fmt.Fprintf(&cycleImports, "\t\timport %q from %q\n", imp.ImportPath, imp.SourcePath)
+			}
+		}
+
+		cycleError := fmt.Errorf(
+			"Import cycle between %[1]d targets:\n%[2]s"+
+				"Possible solutions:\n"+
+				"\t1. Move the code imported by both targets to a separate target.\n"+
+				"\t2. Disable Gazelle import cycle validation using '# aspect:%[3]s off'",
+			len(cycle), cycleImports.String(), Directive_ValidateImportCycles,
+		)
+
+		from, _ := label.Parse(cycle[0])
+		reportResolutionErrors(start.c, cfg.ValidateImportCycles(), from, []error{cycleError})
+	}
+}
+
+// Find the shortest import cycle from the first target of a strongly connected
+// component back to itself.
+func findImportCycle(graph map[string][]string, component []string) []string {
+	start := component[0]
+
+	// Breadth-first search of the component, recording the target importing each target
+	importedBy := map[string]string{}
+	queue := []string{start}
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+
+		deps := slices.Clone(graph[key])
+		slices.Sort(deps)
+		for _, dep := range deps {
+			if dep == start {
+				cycle := []string{key}
+				for key != start {
+					key = importedBy[key]
+					cycle = append(cycle, key)
+				}
+				slices.Reverse(cycle)
+				return cycle
+			}
+
+			if _, visited := importedBy[dep]; !visited && slices.Contains(component, dep) {
+				importedBy[dep] = key
+				queue = append(queue, dep)
+			}
+		}
+	}
+
+	return component
+}
+
+// Group the nodes of a graph into strongly connected components, nodes depending
+// on each other directly or indirectly are in the same component.
+//
+// Components and the nodes of each component are sorted.
+func toStronglyConnectedComponents(graph map[string][]string) [][]string {
+	nodes := make([]string, 0, len(graph))
+	for node := range graph {
+		nodes = append(nodes, node)
+	}
+	slices.Sort(nodes)
+
+	// Tarjan's algorithm
+	index := make(map[string]int, len(nodes))
+	lowLink := make(map[string]int, len(nodes))
+	onStack := make(map[string]bool, len(nodes))
+	stack := []string{}
+	components := [][]string{}
+
+	var connect func(node string)
+	connect = func(node string) {
+		index[node] = len(index)
+		lowLink[node] = index[node]
+		stack = append(stack, node)
+		onStack[node] = true
+
+		deps := slices.Clone(graph[node])
+		slices.Sort(deps)
+		for _, dep := range slices.Compact(deps) {
+			if _, visited := index[dep]; !visited {
+				connect(dep)
+				lowLink[node] = min(lowLink[node], lowLink[dep])
+			} else if onStack[dep] {
+				lowLink[node] = min(lowLink[node], index[dep])
+			}
+		}
+
+		if lowLink[node] != index[node] {
+			return
+		}
+
+		component := []string{}
+		for {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[n] = false
+			component = append(component, n)
+			if n == node {
+				break
+			}
+		}
+		slices.Sort(component)
+		components = append(components, component)
+	}
+
+	for _, node := range nodes {
+		if _, visited := index[node]; !visited {
+			connect(node)
+		}
+	}
+
+	slices.SortFunc(components, func(a, b []string) int {
+		return strings.Compare(a[0], b[0])
+	})
+
+	return components
+}
@@ -18,9 +18,16 @@ package gazelle
 
 import (
 	"bytes"
+	"crypto/md5"
 	"encoding/gob"
+	"encoding/hex"
 	"fmt"
+	"iter"
+	"maps"
+	"os"
 	"path"
+	"regexp"
+	"slices"
 	"strings"
 
 	common "github.com/aspect-build/aspect-gazelle/common"
@@ -85,7 +92,7 @@ func (ts *typeScriptLang) GenerateRules(args language.GenerateArgs) language.Gen
 	cfg := args.Config.Exts[LanguageName].(*JsGazelleConfig)
 
 	// Collect any labels that could be imported
-	ts.collectFileLabels(args)
+	ts.collectFileLabels(cfg, args)
 
 	// When we return empty, we mean that we don't generate anything, but this
 	// still triggers the indexing for all the TypeScript targets in this package.
@@ -100,6 +107,7 @@ func (ts *typeScriptLang) GenerateRules(args language.GenerateArgs) language.Gen
 
 	ts.addPackageRules(cfg, args, &result)
 	ts.addSourceRules(cfg, args, &result)
+	ts.addBinaryRules(cfg, args, &result)
 
 	if cfg.GetTsConfigGenerationEnabled() {
 		ts.addTsConfigRules(cfg, args, &result)
@@ -144,6 +152,9 @@ func (ts *typeScriptLang) addSourceRules(cfg *JsGazelleConfig, args language.Gen
 	// Collect data files which *may* be added to a target if imported within the sources.
 	dataFiles := treeset.NewWithStringComparator()
 
+	// Collect assets which *may* be added to a target if imported within the sources.
+	assetFiles := treeset.NewWithStringComparator()
+
 	// Calculate the tsconfig rootDir relative to the current directory being walked
 	tsconfigRootDir := "."
 	if tsconfig != nil {
@@ -158,11 +169,31 @@ func (ts *typeScriptLang) addSourceRules(cfg *JsGazelleConfig, args language.Gen
 		}
 	}
 
+	// The current directory relative to the tsconfig directory
+	tsconfigFileDir := "."
+	if tsconfig != nil && tsconfigRel != args.Rel {
+		tsconfigFileDir = strings.TrimPrefix(args.Rel, tsconfigRel+"/")
+		if tsconfigRel == "" {
+			tsconfigFileDir = args.Rel
+		}
+	}
+
+	// Only source files are checked against the tsconfig compilation set, generated files
+	// may be produced outside the tsconfig include/exclude/files.
+	checkCompilationSet := tsconfig != nil && tsconfig.HasCompilationSet() && cfg.GetTsConfigCompilationSetEnabled()
+
 	// Util for adding a file to a source group or the data files.
-	processPotentialSourceFile := func(groups *treemap.Map, file string) {
+	processPotentialSourceFile := func(groups *treemap.Map, file string, isGenerated bool) {
 		fileExt := path.Ext(file)
-		if isSourceFileExt(fileExt) {
-			if target := cfg.GetFileSourceTarget(file, tsconfigRootDir); target != nil {
+		if isSourceFileExt(fileExt) || (parser.IsComponentFileExt(fileExt) && cfg.GetFileSourceTarget(file, tsconfigRootDir) != nil) {
+			target := cfg.GetFileSourceTarget(file, tsconfigRootDir)
+
+			if target != nil && !isGenerated && checkCompilationSet && !tsconfig.IsFileIncluded(path.Join(tsconfigFileDir, file)) {
+				// Source files outside the tsconfig compilation set, may still be considered "data".
+				fmt.Fprintf(os.Stderr, "Warning: src %q not included by tsconfig %q, adding as data file\n", path.Join(args.Rel, file), path.Join(tsconfigRel, tsconfig.ConfigName))
+
+				dataFiles.Add(file)
+			} else if target != nil {
 				// Source files belonging to a target group.
 				BazelLog.Tracef("add '%s' src '%s/%s'", target.name, args.Rel, file)
 
@@ -185,16 +216,21 @@ func (ts *typeScriptLang) addSourceRules(cfg *JsGazelleConfig, args language.Gen
 		if isDataFileExt(fileExt) {
 			dataFiles.Add(file)
 		}
+
+		// Assets such as stylesheets and images.
+		if cfg.IsAssetFile(file) {
+			assetFiles.Add(file)
+		}
 	}
 
 	// Collect source files.
 	for _, file := range args.RegularFiles {
-		processPotentialSourceFile(sourceFileGroups, file)
+		processPotentialSourceFile(sourceFileGroups, file, false)
 	}
 
 	// Collect generated files.
 	for _, file := range args.GenFiles {
-		processPotentialSourceFile(generatedFileGroups, file)
+		processPotentialSourceFile(generatedFileGroups, file, true)
 	}
 
 	// Determine if this is a pnpm project and if a package target should be generated.
@@ -204,6 +240,26 @@ func (ts *typeScriptLang) addSourceRules(cfg *JsGazelleConfig, args language.Gen
 	// The package/directory name variable value used to render the target names.
 	packageName := toDefaultTargetName(args, DefaultRootTargetName)
 
+	// Packages of only assets such as images or fonts have a library of the assets
+	// for other packages to depend on.
+	isAssetsOnly := sourceFileGroups.Empty() && generatedFileGroups.Empty() && !assetFiles.Empty()
+
+	// The names of all targets generated in the package, reserved when naming the
+	// targets of individual files.
+	targetNames := make(map[string]bool, len(result.Gen))
+	for _, r := range result.Gen {
+		targetNames[r.Name()] = true
+	}
+	for _, group := range cfg.GetSourceTargets() {
+		targetNames[cfg.RenderSourceTargetName(group.name, packageName, hasPackageTarget)] = true
+	}
+	if hasPackageTarget {
+		targetNames[cfg.RenderNpmPackageTargetName(packageName)] = true
+	}
+	if tsconfig := ts.tsconfig.GetTsConfigFile(args.Rel); tsconfig != nil && cfg.GetTsConfigGenerationEnabled() {
+		targetNames[cfg.RenderTsConfigName(tsconfig.ConfigName)] = true
+	}
+
 	// Create rules for each target group.
 	sourceRules := treemap.NewWithStringComparator()
 	for _, group := range cfg.GetSourceTargets() {
@@ -212,8 +268,13 @@ func (ts *typeScriptLang) addSourceRules(cfg *JsGazelleConfig, args language.Gen
 
 		var ruleSrcs, ruleGenSrcs *treeset.Set
 
+		// Rules with custom sources are never split into rules per file.
+		isFileGranularity := cfg.granularity == GranularityFile
+
 		// If the rule has it's own custom list of sources then parse and use that list.
 		if existing := ruleUtils.GetFileRuleByName(args, ruleName); existing != nil && sourceRuleKinds.Contains(existing.Kind()) && isCustomSrcs(existing.Attr("srcs")) {
+			isFileGranularity = false
+
 			customSrcs, err := ruleUtils.ExpandSrcs(args.RegularFiles, existing.Attr("srcs"))
 			if err != nil {
 				BazelLog.Infof("Failed to expand custom srcs %s:%s - %v", args.Rel, existing.Name(), err)
@@ -234,9 +295,35 @@ func (ts *typeScriptLang) addSourceRules(cfg *JsGazelleConfig, args language.Gen
 			}
 		}
 
-		if ruleSrcs == nil || ruleSrcs.Empty() {
+		if (ruleSrcs == nil || ruleSrcs.Empty()) && group.name == DefaultLibraryName && isAssetsOnly {
+			sourceRules.Put(group.name, ts.addAssetsRule(cfg, args, ruleName, assetFiles, result))
+		} else if ruleSrcs == nil || ruleSrcs.Empty() {
 			// No sources for this source group. Remove the rule if it exists.
 			ruleUtils.RemoveRule(args, ruleName, sourceRuleKinds, result)
+		} else if isFileGranularity {
+			// Add or edit/merge a rule per source file of this source group.
+			srcRule, srcGenErr := ts.addFileRules(
+				cfg,
+				tsconfigRel,
+				tsconfig,
+				args,
+				group,
+				ruleName,
+				ruleSrcs,
+				ruleGenSrcs,
+				dataFiles,
+				assetFiles,
+				targetNames,
+				result,
+			)
+			if srcGenErr != nil {
+				common.GenerationErrorf(args.Config, "Source rule generation error: %v", srcGenErr)
+				return
+			}
+
+			if srcRule != nil {
+				sourceRules.Put(group.name, srcRule)
+			}
 		} else {
 			// Add or edit/merge a rule for this source group.
 			srcRule, srcGenErr := ts.addProjectRule(
@@ -249,6 +336,8 @@ func (ts *typeScriptLang) addSourceRules(cfg *JsGazelleConfig, args language.Gen
 				ruleSrcs,
 				ruleGenSrcs,
 				dataFiles,
+				assetFiles,
+				nil,
 				result,
 			)
 			if srcGenErr != nil {
@@ -257,6 +346,15 @@ func (ts *typeScriptLang) addSourceRules(cfg *JsGazelleConfig, args language.Gen
 			}
 
 			sourceRules.Put(group.name, srcRule)
+
+			// Run the tests with the configured test runner.
+			if group.testonly && cfg.getTestRunner() != nil {
+				ts.addTestRunnerRule(cfg, args, srcRule, result)
+			}
+		}
+
+		if _, hasRule := sourceRules.Get(group.name); group.testonly && (cfg.getTestRunner() == nil || !hasRule) {
+			removeTestRunnerRules(args, ruleName, result)
 		}
 	}
 
@@ -318,13 +416,54 @@ func (ts *typeScriptLang) addPackageRule(cfg *JsGazelleConfig, args language.Gen
 		}
 	}
 
-	// Add the package.json if not in the src
-	// TODO: why not always add it?
-	// TODO: declare import on it instead if it's in another rule?
-	if dataFiles.Contains(NpmPackageFilename) {
-		dataFiles.Remove(NpmPackageFilename)
-		npmPackageInfo.sources.Add(NpmPackageFilename)
+	// The files published by `npm pack` such as the package.json, README and "files"
+	packFiles, err := loadNpmPackFiles(args.Config, args.Rel)
+	if err != nil {
+		common.MisconfiguredErrorf(args.Config, "Failed to load %q published files: %v", packageJsonPath, err)
+		return
+	}
+
+	packageFiles, err := common.GetSourceRegularFiles(args.Rel)
+	if err != nil {
+		BazelLog.Errorf("Failed to fetch source files of %q: %v", args.Rel, err)
+	}
+
+	alwaysPublished := []string{}
+	for _, f := range packageFiles {
+		if slices.Contains(args.Config.ValidBuildFileNames, path.Base(f)) {
+			continue
+		}
+
+		if isNpmPackAlwaysPublished(f) {
+			alwaysPublished = append(alwaysPublished, f)
+		} else if !packFiles.filtered || !packFiles.isPublished(f) {
+			continue
+		}
+
+		if dataFiles.Contains(f) {
+			dataFiles.Remove(f)
+			npmPackageInfo.sources.Add(f)
+		} else if ext := path.Ext(f); !isSourceFileExt(ext) && !isDataFileExt(ext) && !cfg.IsAssetFile(f) {
+			// Files not part of any other target such as documentation
+			npmPackageInfo.sources.Add(f)
+		}
+	}
+
+	// Files of the tsconfig output directories are only produced by the build
+	outDirs := []string{}
+	if tsconfigRel, tsconfig := ts.tsconfig.FindConfig(args.Rel); tsconfig != nil {
+		for _, dir := range []string{tsconfig.OutDir, tsconfig.DeclarationDir} {
+			dir = path.Join(tsconfigRel, dir)
+			if args.Rel != "" {
+				dir, _ = strings.CutPrefix(dir, args.Rel+"/")
+			}
+			if dir != "." && dir != args.Rel {
+				outDirs = append(outDirs, dir)
+			}
+		}
 	}
+	packFiles.collectUnmatched(args.Rel, packageFiles, outDirs)
+	npmPackageInfo.unmatchedFiles = packFiles.unmatched
 
 	packageTargetName := cfg.RenderNpmPackageTargetName(packageName)
 	packageTargetKind := NpmPackageKind
@@ -337,6 +476,19 @@ func (ts *typeScriptLang) addPackageRule(cfg *JsGazelleConfig, args language.Gen
 	npmPackage := rule.NewRule(packageTargetKind, packageTargetName)
 	npmPackage.SetPrivateAttr("ts_project_info", &npmPackageInfo.TsProjectInfo)
 	npmPackage.SetAttr("srcs", npmPackageInfo.sources.Values())
+
+	// Restrict the outputs of the source targets to the published files
+	if packageTargetKind == NpmPackageKind && packFiles.filtered {
+		if packFiles.include != nil {
+			includes := slices.Concat(alwaysPublished, packFiles.entryFiles, packFiles.includeSrcsPatterns())
+			slices.Sort(includes)
+			npmPackage.SetAttr("include_srcs_patterns", slices.Compact(includes))
+		}
+		if excludes := packFiles.excludeSrcsPatterns(packageFiles); len(excludes) > 0 {
+			npmPackage.SetAttr("exclude_srcs_patterns", excludes)
+		}
+	}
+
 	npmPackage.SetAttr("visibility", []string{npmPackageVisibility})
 
 	result.Gen = append(result.Gen, npmPackage)
@@ -346,6 +498,135 @@ func (ts *typeScriptLang) addPackageRule(cfg *JsGazelleConfig, args language.Gen
 	BazelLog.Infof("add rule '%s' '%s:%s'", cfg.packageTargetKind, args.Rel, packageTargetName)
 }
 
+// A js_binary() entry point and where it was declared.
+type binaryEntryPoint struct {
+	entryPoint string
+	sourcePath string
+}
+
+// Add js_binary() rules for package.json 'bin' entries and js_binary_files entry points.
+func (ts *typeScriptLang) addBinaryRules(cfg *JsGazelleConfig, args language.GenerateArgs, result *language.GenerateResult) {
+	// Binary name => entry point relative to the BUILD
+	entryPoints := make(map[string]binaryEntryPoint)
+
+	// Entry points declared via the pnpm project package.json 'bin'
+	if ts.pnpmProjects.IsProject(args.Rel) && common.WalkHasPath(args.Rel, NpmPackageFilename) {
+		packageJsonPath := path.Join(args.Rel, NpmPackageFilename)
+
+		parserCache := cache.Get(args.Config)
+		packageBins, _, err := parserCache.LoadOrStoreFile(args.Config.RepoRoot, packageJsonPath, "parsePackageJsonBin", func(path string, content []byte) (any, error) {
+			return node.ParsePackageJsonBin(bytes.NewReader(content))
+		})
+		if err != nil {
+			common.MisconfiguredErrorf(args.Config, "Failed to parse %q bin: %v", packageJsonPath, err)
+			return
+		}
+
+		for name, bin := range packageBins.(map[string]string) {
+			entryPoints[name] = binaryEntryPoint{
+				entryPoint: bin,
+				sourcePath: packageJsonPath,
+			}
+		}
+	}
+
+	// Entry points declared via the js_binary_files directive
+	for _, file := range args.RegularFiles {
+		if !isSourceFileExt(path.Ext(file)) || isDeclarationFileType(file) || !cfg.IsBinaryFile(file) {
+			continue
+		}
+
+		name := strings.TrimSuffix(file, path.Ext(file))
+		if _, exists := entryPoints[name]; exists {
+			BazelLog.Debugf("js_binary %s:%s already declared by %q", args.Rel, name, NpmPackageFilename)
+			continue
+		}
+
+		entryPoints[name] = binaryEntryPoint{
+			entryPoint: ts.toTsProjectOutput(args.Rel, file),
+			sourcePath: path.Join(args.Rel, file),
+		}
+	}
+
+	// The names of rules already generated which binaries must not conflict with
+	generatedNames := make(map[string]bool, len(result.Gen))
+	for _, r := range result.Gen {
+		generatedNames[r.Name()] = true
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(entryPoints)) {
+		if generatedNames[name] {
+			BazelLog.Warnf("Skipping js_binary %s:%s, a target of the same name is already generated", args.Rel, name)
+			continue
+		}
+
+		if colError := ruleUtils.CheckCollisionErrors(name, JsBinaryKind, binaryRuleKinds, args); colError != nil {
+			common.GenerationErrorf(args.Config, "Binary rule generation error: %v", colError)
+			return
+		}
+
+		ts.addBinaryRule(cfg, args, name, entryPoints[name], result)
+	}
+}
+
+// The js file a source file of the directory is transpiled to, relative to the directory.
+//
+// Sources of a tsconfig with an outDir are transpiled into the outDir, unless the outDir
+// is not within the directory.
+func (ts *typeScriptLang) toTsProjectOutput(rel, f string) string {
+	jsFile := toJsFile(f)
+
+	tsconfigRel, tsconfig := ts.tsconfig.FindConfig(rel)
+	if tsconfig == nil || !isTranspiledSourceFileType(f) {
+		return jsFile
+	}
+
+	// The output relative to the tsconfig, then the directory
+	tsconfigFile := path.Join(rel, jsFile)
+	if tsconfigRel != "" {
+		tsconfigFile = tsconfigFile[len(tsconfigRel)+1:]
+	}
+	outFile := path.Join(tsconfigRel, tsconfig.ToOutDir(tsconfigFile))
+	if rel == "" {
+		return outFile
+	}
+	if outFile, isWithin := strings.CutPrefix(outFile, rel+"/"); isWithin {
+		return outFile
+	}
+
+	BazelLog.Debugf("tsconfig outDir of %q not within %q", path.Join(rel, f), rel)
+	return jsFile
+}
+
+func (ts *typeScriptLang) addBinaryRule(cfg *JsGazelleConfig, args language.GenerateArgs, name string, entryPoint binaryEntryPoint, result *language.GenerateResult) {
+	info := newTsProjectInfo()
+
+	// The entry point is resolved to the target producing the file such as a ts_project()
+	// transpiling the entry point or a js_library() containing it.
+	if !cfg.IsImportIgnored(entryPoint.entryPoint) {
+		info.AddImport(ImportStatement{
+			ImportSpec: resolve.ImportSpec{
+				Lang: LanguageName,
+				Imp:  path.Join(args.Rel, entryPoint.entryPoint),
+			},
+			ImportPath: entryPoint.entryPoint,
+			SourcePath: entryPoint.sourcePath,
+
+			// The entry point may be a plain file not produced by any target.
+			Optional: true,
+		})
+	}
+
+	binaryRule := rule.NewRule(JsBinaryKind, name)
+	binaryRule.SetAttr("entry_point", entryPoint.entryPoint)
+
+	result.Gen = append(result.Gen, binaryRule)
+	result.Imports = append(result.Imports, info)
+	result.RelsToIndex = append(result.RelsToIndex, ts.tsPackageInfoToRelsToIndex(cfg, args, info)...)
+
+	BazelLog.Infof("add rule '%s' '%s:%s'", binaryRule.Kind(), args.Rel, binaryRule.Name())
+}
+
 func (ts *typeScriptLang) addTsConfigRules(cfg *JsGazelleConfig, args language.GenerateArgs, result *language.GenerateResult) {
 	tsconfig := ts.tsconfig.GetTsConfigFile(args.Rel)
 	if tsconfig == nil {
@@ -357,6 +638,8 @@ func (ts *typeScriptLang) addTsConfigRules(cfg *JsGazelleConfig, args language.G
 		imports.AddImport(impt)
 	}
 
+	ts.validateTsConfigReferences(args, tsconfig)
+
 	tsconfigName := cfg.RenderTsConfigName(tsconfig.ConfigName)
 	tsconfigRule := rule.NewRule(TsConfigKind, tsconfigName)
 	tsconfigRule.SetAttr("src", tsconfig.ConfigName)
@@ -367,19 +650,38 @@ func (ts *typeScriptLang) addTsConfigRules(cfg *JsGazelleConfig, args language.G
 	result.RelsToIndex = append(result.RelsToIndex, ts.tsPackageInfoToRelsToIndex(cfg, args, imports)...)
 }
 
+// Report tsconfig "references" which tsc would reject: references to projects that
+// do not exist or are not composite, and circular references.
+func (ts *typeScriptLang) validateTsConfigReferences(args language.GenerateArgs, tsconfig *typescript.TsConfig) {
+	tsconfigPath := path.Join(tsconfig.ConfigDir, tsconfig.ConfigName)
+
+	for _, reference := range tsconfig.References {
+		referenceFile, referenced := ts.tsconfig.GetReferencedTsConfig(args.Config.RepoRoot, reference)
+		if referenced == nil {
+			fmt.Fprintf(os.Stderr, "Warning: tsconfig %q references %q which does not exist or is invalid\n", tsconfigPath, referenceFile)
+		} else if referenced.Composite == nil || !*referenced.Composite {
+			fmt.Fprintf(os.Stderr, "Warning: tsconfig %q references %q which is not a composite project, referenced projects must set \"composite\": true\n", tsconfigPath, referenceFile)
+		}
+	}
+
+	if cycle := ts.tsconfig.FindReferenceCycle(args.Config.RepoRoot, tsconfigPath); cycle != nil {
+		fmt.Fprintf(os.Stderr, "Warning: tsconfig %q has circular project references: %s\n", tsconfigPath, strings.Join(cycle, " -> "))
+	}
+}
+
 func (ts *typeScriptLang) collectTsConfigImports(cfg *JsGazelleConfig, args language.GenerateArgs, tsconfig *typescript.TsConfig) []ImportStatement {
 	imports := make([]ImportStatement, 0)
 
 	SourcePath := path.Join(tsconfig.ConfigDir, tsconfig.ConfigName)
 
-	if tsconfig.Extends != "" {
-		if !cfg.IsImportIgnored(tsconfig.Extends) {
+	for _, extends := range tsconfig.Extends {
+		if !cfg.IsImportIgnored(extends) {
 			imports = append(imports, ImportStatement{
 				ImportSpec: resolve.ImportSpec{
 					Lang: LanguageName,
-					Imp:  toImportSpecPath(SourcePath, tsconfig.Extends),
+					Imp:  toImportSpecPath(SourcePath, extends),
 				},
-				ImportPath: tsconfig.Extends,
+				ImportPath: extends,
 				SourcePath: SourcePath,
 			})
 		}
@@ -400,18 +702,10 @@ func (ts *typeScriptLang) collectTsConfigImports(cfg *JsGazelleConfig, args lang
 	}
 
 	for _, reference := range tsconfig.References {
-		referenceFile := cfg.tsconfigName
-		referenceDir := "."
-		if strings.HasSuffix(reference, ".json") {
-			referenceFile = reference
-		} else {
-			referenceDir = reference
-		}
-
 		imports = append(imports, ImportStatement{
 			ImportSpec: resolve.ImportSpec{
 				Lang: LanguageName,
-				Imp:  path.Join(referenceDir, referenceFile),
+				Imp:  ts.tsconfig.GetReferenceFile(reference),
 			},
 			ImportPath: reference,
 			SourcePath: SourcePath,
@@ -424,16 +718,33 @@ func (ts *typeScriptLang) collectTsConfigImports(cfg *JsGazelleConfig, args lang
 func (ts *typeScriptLang) addTsProtoRules(cfg *JsGazelleConfig, args language.GenerateArgs, result *language.GenerateResult) {
 	protoLibraries, emptyLibraries := proto.GetProtoLibraries(args, result)
 
-	// Generate one ts_proto_library() per proto_library()
+	// Message types and connect-es services are both generated by ts_proto_library()
+	hasTsProtoLibrary := cfg.HasProtoFlavor(ProtoFlavorEs) || cfg.HasProtoFlavor(ProtoFlavorConnect)
+
+	// Generate one ts_proto_library() and/or js_grpc_web_library() per proto_library()
 	for _, protoLibrary := range protoLibraries {
 		ruleName := cfg.RenderTsProtoLibraryName(protoLibrary.Name())
-		ts.addTsProtoRule(cfg, args, protoLibrary, ruleName, result)
+		if hasTsProtoLibrary {
+			ts.addTsProtoRule(cfg, args, protoLibrary, ruleName, result)
+		} else {
+			ruleUtils.RemoveRule(args, ruleName, sourceRuleKinds, result)
+		}
+
+		grpcWebRuleName := cfg.RenderGrpcWebLibraryName(protoLibrary.Name())
+		if cfg.HasProtoFlavor(ProtoFlavorGrpcWeb) {
+			ts.addGrpcWebRule(cfg, args, protoLibrary, grpcWebRuleName, result)
+		} else {
+			ruleUtils.RemoveRule(args, grpcWebRuleName, grpcWebRuleKinds, result)
+		}
 	}
 
-	// Remove any ts_proto_library() targets associated with now-empty proto_library() targets
+	// Remove any ts_proto_library() and js_grpc_web_library() targets associated with now-empty proto_library() targets
 	for _, emptyLibrary := range emptyLibraries {
 		ruleName := cfg.RenderTsProtoLibraryName(emptyLibrary.Name())
 		ruleUtils.RemoveRule(args, ruleName, sourceRuleKinds, result)
+
+		grpcWebRuleName := cfg.RenderGrpcWebLibraryName(emptyLibrary.Name())
+		ruleUtils.RemoveRule(args, grpcWebRuleName, grpcWebRuleKinds, result)
 	}
 }
 
@@ -451,12 +762,24 @@ func (ts *typeScriptLang) addTsProtoRule(cfg *JsGazelleConfig, args language.Gen
 		tsProtoLibrary.SetAttr("node_modules", node_modulesLabelStr)
 	}
 
+	// connect-es services, the messages are always generated
+	if cfg.HasProtoFlavor(ProtoFlavorConnect) {
+		tsProtoLibrary.SetAttr("gen_connect_es", true)
+	} else if cfg.IsProtoFlavorConfigured() {
+		// Only removed when the flavors are configured, gen_connect_es is otherwise
+		// managed by the user. Delete from the existing rule to bypass merging.
+		if existing := ruleUtils.GetFileRuleByName(args, ruleName); existing != nil && existing.Kind() == ruleUtils.MapKind(args, TsProtoLibraryKind) {
+			existing.DelAttr("gen_connect_es")
+		}
+	}
+
 	sourceFiles := protoLibrary.AttrStrings("srcs")
 
 	// Persist the proto_library(srcs)
 	tsProtoLibrary.SetPrivateAttr("proto_library_srcs", sourceFiles)
 
-	protoImports, err := ts.collectProtoImports(cfg, args, sourceFiles)
+	// The generated code imports the messages of imported protos
+	protoImports, err := ts.collectProtoImports(cfg, args, sourceFiles, "_pb")
 	if err != nil {
 		common.GenerationErrorf(args.Config, "Proto import collection error: %v", err)
 		return
@@ -474,13 +797,45 @@ func (ts *typeScriptLang) addTsProtoRule(cfg *JsGazelleConfig, args language.Gen
 	BazelLog.Infof("add rule '%s' '%s:%s'", tsProtoLibrary.Kind(), args.Rel, tsProtoLibrary.Name())
 }
 
+// Add a js_grpc_web_library() of the grpc-web services of a proto_library().
+func (ts *typeScriptLang) addGrpcWebRule(cfg *JsGazelleConfig, args language.GenerateArgs, protoLibrary *rule.Rule, ruleName string, result *language.GenerateResult) {
+	protoRuleLabel := label.New("", args.Rel, protoLibrary.Name())
+
+	grpcWebLibrary := rule.NewRule(JsGrpcWebLibraryKind, ruleName)
+	grpcWebLibrary.SetAttr("protos", []string{protoRuleLabel.Rel("", args.Rel).String()})
+
+	sourceFiles := protoLibrary.AttrStrings("srcs")
+
+	// Persist the proto_library(srcs)
+	grpcWebLibrary.SetPrivateAttr("proto_library_srcs", sourceFiles)
+
+	// The generated code depends on the grpc-web library of imported protos
+	protoImports, err := ts.collectProtoImports(cfg, args, sourceFiles, "_grpc_web_pb")
+	if err != nil {
+		common.GenerationErrorf(args.Config, "Proto import collection error: %v", err)
+		return
+	}
+
+	imports := newTsProjectInfo()
+	for _, impt := range protoImports {
+		imports.AddImport(impt)
+	}
+
+	result.Gen = append(result.Gen, grpcWebLibrary)
+	result.Imports = append(result.Imports, imports)
+	result.RelsToIndex = append(result.RelsToIndex, ts.tsPackageInfoToRelsToIndex(cfg, args, imports)...)
+
+	BazelLog.Infof("add rule '%s' '%s:%s'", grpcWebLibrary.Kind(), args.Rel, grpcWebLibrary.Name())
+}
+
 func hasTranspiledSources(sourceFiles *treeset.Set) bool {
 	return sourceFiles.Any(func(_ int, f any) bool {
 		return isTranspiledSourceFileType(f.(string))
 	})
 }
 
-func (ts *typeScriptLang) addProjectRule(cfg *JsGazelleConfig, tsconfigRel string, tsconfig *typescript.TsConfig, args language.GenerateArgs, group *TargetGroup, targetName string, sourceFiles, genFiles, dataFiles *treeset.Set, result *language.GenerateResult) (*rule.Rule, error) {
+// The parsed source files may contain the results of files already parsed, other files are parsed.
+func (ts *typeScriptLang) addProjectRule(cfg *JsGazelleConfig, tsconfigRel string, tsconfig *typescript.TsConfig, args language.GenerateArgs, group *TargetGroup, targetName string, sourceFiles, genFiles, dataFiles, assetFiles *treeset.Set, parsed map[string]parseResult, result *language.GenerateResult) (*rule.Rule, error) {
 	// Check for name-collisions with the rule being generated.
 	colError := ruleUtils.CheckCollisionErrors(targetName, TsProjectKind, sourceRuleKinds, args)
 	if colError != nil {
@@ -503,16 +858,29 @@ func (ts *typeScriptLang) addProjectRule(cfg *JsGazelleConfig, tsconfigRel strin
 		genFiles.Each(func(_ int, f any) { info.sources.Add(f.(string)) })
 	}
 
+	// Aliases of the test runner such as the jest moduleNameMapper
+	var testImportMappings []moduleNameMapping
+	if group.testonly && cfg.getTestRunner() != nil {
+		testImportMappings = cfg.getTestRunner().moduleNameMapper(args.Config)
+	}
+
 	// Parse source files, do not parse generated files that are not source files.
-	for result := range ts.parseFiles(cfg, args, sourceFiles) {
+	for result := range ts.parseFilesOnce(cfg, args, sourceFiles, parsed) {
 		if result.Error != nil {
 			return nil, result.Error
 		}
 
 		for _, sourceImport := range result.Imports {
+			if len(testImportMappings) > 0 {
+				sourceImport = cfg.getTestRunner().mapImport(testImportMappings, sourceImport)
+			}
 			info.AddImport(sourceImport)
 		}
 
+		if cfg.narrowBarrelImports {
+			ts.addModuleExports(result.SourcePath, result.Exports, result.ReExports, info.sources.Size() == 1)
+		}
+
 		for _, sourceModule := range result.Modules {
 			ts.addModuleDeclaration(sourceModule, &label.Label{
 				Name:     targetName,
@@ -535,6 +903,17 @@ func (ts *typeScriptLang) addProjectRule(cfg *JsGazelleConfig, tsconfigRel strin
 		})
 	}
 
+	// tsconfig project references of the tsconfig within this directory
+	if tsconfig != nil && tsconfigRel == args.Rel {
+		if group.name == DefaultLibraryName {
+			info.tsconfig = path.Join(tsconfig.ConfigDir, tsconfig.ConfigName)
+		}
+
+		for _, reference := range tsconfig.References {
+			info.references = append(info.references, ts.tsconfig.GetReferenceFile(reference))
+		}
+	}
+
 	// Data file lookup map. Workspace path => local path
 	dataFileWorkspacePaths := make(map[string]string, dataFiles.Size())
 	for it := dataFiles.Iterator(); it.Next(); {
@@ -557,23 +936,48 @@ func (ts *typeScriptLang) addProjectRule(cfg *JsGazelleConfig, tsconfigRel strin
 		}
 	}
 
+	// Add any imported assets within this package.
+	ts.addImportedAssets(cfg, args, info, assetFiles)
+
 	// A rule of the same name might already exist
 	existing := ruleUtils.GetFileRuleByName(args, targetName)
 
+	// Tests run by a test runner are a testonly library of the test runner rule.
 	ruleKind := JsLibraryKind
-	if group.testonly {
+	if group.testonly && cfg.getTestRunner() == nil {
 		ruleKind = JsTestKind
 	}
 	sourceRule := rule.NewRule(ruleKind, targetName)
 
+	if group.testonly && cfg.getTestRunner() != nil {
+		sourceRule.SetAttr("testonly", true)
+	}
+
 	// TODO: this seems like a hack...
 	// Gazelle should support new rules changing the type of existing rules?
 	if existing != nil && existing.Kind() != ruleKind {
+		// The testonly library generated for a test runner rule is no longer testonly as
+		// a js_test(), testonly libraries not run by a generated test runner rule are kept
+		if ruleKind == JsTestKind && existing.Kind() == JsLibraryKind && hasTestRunnerRule(args, targetName) {
+			existing.DelAttr("testonly")
+		}
+
 		existing.SetKind(ruleKind)
 	}
 
 	sourceRule.SetPrivateAttr("ts_project_info", info)
-	sourceRule.SetAttr("srcs", info.sources.Values())
+
+	// Assets are the ts_project(assets), or part of the srcs of other rule kinds.
+	if ruleKind == TsProjectKind {
+		sourceRule.SetAttr("srcs", info.sources.Values())
+		if !info.assets.Empty() {
+			sourceRule.SetAttr("assets", info.assets.Values())
+		}
+	} else {
+		srcs := treeset.NewWithStringComparator(info.sources.Values()...)
+		srcs.Add(info.assets.Values()...)
+		sourceRule.SetAttr("srcs", srcs.Values())
+	}
 
 	if len(group.visibility) > 0 {
 		sourceRule.SetAttr("visibility", group.visibility)
@@ -758,14 +1162,126 @@ func (ts *typeScriptLang) addProjectRule(cfg *JsGazelleConfig, tsconfigRel strin
 	return sourceRule, nil
 }
 
+// Add a js_library of the assets of a package with no sources.
+func (ts *typeScriptLang) addAssetsRule(cfg *JsGazelleConfig, args language.GenerateArgs, targetName string, assetFiles *treeset.Set, result *language.GenerateResult) *rule.Rule {
+	info := newTsProjectInfo()
+
+	assets := make([]string, 0, assetFiles.Size())
+	for it := assetFiles.Iterator(); it.Next(); {
+		assets = append(assets, it.Value().(string))
+	}
+	assetFiles.Clear()
+
+	ts.addAssets(cfg, args, info, assetFiles, assets)
+
+	existing := ruleUtils.GetFileRuleByName(args, targetName)
+	if existing != nil && existing.Kind() != JsLibraryKind {
+		existing.SetKind(JsLibraryKind)
+	}
+
+	assetsRule := rule.NewRule(JsLibraryKind, targetName)
+	assetsRule.SetPrivateAttr("ts_project_info", info)
+	assetsRule.SetAttr("srcs", info.assets.Values())
+
+	result.Gen = append(result.Gen, assetsRule)
+	result.Imports = append(result.Imports, info)
+	result.RelsToIndex = append(result.RelsToIndex, ts.tsPackageInfoToRelsToIndex(cfg, args, info)...)
+
+	BazelLog.Infof("add rule '%s' '%s:%s'", assetsRule.Kind(), args.Rel, assetsRule.Name())
+
+	return assetsRule
+}
+
+// Add the assets within this package imported by the project sources.
+func (ts *typeScriptLang) addImportedAssets(cfg *JsGazelleConfig, args language.GenerateArgs, info *TsProjectInfo, assetFiles *treeset.Set) {
+	assets := []string{}
+	for it := info.imports.Iterator(); it.Next(); {
+		if asset, isLocal := toPackagePath(args.Rel, it.Value().(ImportStatement).Imp); isLocal && assetFiles.Contains(asset) {
+			assets = append(assets, asset)
+			assetFiles.Remove(asset)
+		}
+	}
+
+	ts.addAssets(cfg, args, info, assetFiles, assets)
+}
+
+// Add assets to the project following any stylesheet imports of other assets.
+//
+// Assets within this package are removed from the assetFiles to signify they are
+// owned by this project, imports of other assets are added to the project imports.
+func (ts *typeScriptLang) addAssets(cfg *JsGazelleConfig, args language.GenerateArgs, info *TsProjectInfo, assetFiles *treeset.Set, assets []string) {
+	parserCache := cache.Get(args.Config)
+
+	for len(assets) > 0 {
+		asset := assets[0]
+		assets = assets[1:]
+
+		info.assets.Add(asset)
+
+		if !isStylesheetFileExt(path.Ext(asset)) {
+			continue
+		}
+
+		for _, imp := range ts.collectStylesheetImports(cfg, parserCache, args.Config.RepoRoot, path.Join(args.Rel, asset)) {
+			if local, isLocal := toPackagePath(args.Rel, imp.Imp); isLocal && assetFiles.Contains(local) {
+				assets = append(assets, local)
+				assetFiles.Remove(local)
+			} else {
+				info.AddImport(imp)
+			}
+		}
+	}
+}
+
+// Collect the @import and url() dependencies of a stylesheet.
+func (ts *typeScriptLang) collectStylesheetImports(cfg *JsGazelleConfig, parserCache cache.Cache, rootDir, stylesheet string) []ImportStatement {
+	r, _, err := parserCache.LoadOrStoreFile(rootDir, stylesheet, "js.ParseStylesheet", func(filePath string, content []byte) (any, error) {
+		return parser.ParseStylesheet(filePath, content)
+	})
+	if err != nil {
+		// Such as generated stylesheets which do not exist yet
+		BazelLog.Debugf("Failed to parse stylesheet %q: %v", stylesheet, err)
+		return nil
+	}
+
+	imports := r.(parser.ParseResult).Imports
+	results := make([]ImportStatement, 0, len(imports))
+
+	for _, importPath := range imports {
+		if cfg.IsImportIgnored(importPath) {
+			BazelLog.Tracef("%q (%s) import of %q ignored", stylesheet, LanguageName, importPath)
+			continue
+		}
+
+		workspacePath := toStylesheetImportSpecPath(rootDir, stylesheet, importPath)
+
+		results = append(results, ImportStatement{
+			ImportSpec: resolve.ImportSpec{
+				Lang: LanguageName,
+				Imp:  workspacePath,
+			},
+			ImportPath: importPath,
+			SourcePath: stylesheet,
+		})
+
+		BazelLog.Tracef("%q (%s) imports %q (via %q)", stylesheet, LanguageName, workspacePath, importPath)
+	}
+
+	return results
+}
+
 type parseResult struct {
 	SourcePath string
 	Imports    []ImportStatement
 	Modules    []string
+	Exports    []string
+	ReExports  []parser.ReExport
 	Error      error
 }
 
-func (ts *typeScriptLang) collectProtoImports(cfg *JsGazelleConfig, args language.GenerateArgs, sourceFiles []string) ([]ImportStatement, error) {
+// Collect the imports of proto files as imports of the module generated for each
+// imported proto with the moduleSuffix, such as "_pb" for the message types.
+func (ts *typeScriptLang) collectProtoImports(cfg *JsGazelleConfig, args language.GenerateArgs, sourceFiles []string, moduleSuffix string) ([]ImportStatement, error) {
 	results := make([]ImportStatement, 0)
 
 	for _, sourceFile := range sourceFiles {
@@ -787,7 +1303,7 @@ func (ts *typeScriptLang) collectProtoImports(cfg *JsGazelleConfig, args languag
 
 			workspacePath := toImportSpecPath(sourceFile, imp)
 			workspacePath = strings.TrimSuffix(workspacePath, ".proto")
-			workspacePath = workspacePath + "_pb"
+			workspacePath = workspacePath + moduleSuffix
 
 			results = append(results, ImportStatement{
 				ImportSpec: resolve.ImportSpec{
@@ -813,6 +1329,32 @@ func (ts *typeScriptLang) parseFiles(cfg *JsGazelleConfig, args language.Generat
 	})
 }
 
+// Parse the source files, reusing the results of files already parsed.
+func (ts *typeScriptLang) parseFilesOnce(cfg *JsGazelleConfig, args language.GenerateArgs, sourceFiles *treeset.Set, parsed map[string]parseResult) iter.Seq[parseResult] {
+	return func(yield func(parseResult) bool) {
+		unparsed := treeset.NewWithStringComparator()
+		for it := sourceFiles.Iterator(); it.Next(); {
+			if result, isParsed := parsed[it.Value().(string)]; isParsed {
+				if !yield(result) {
+					return
+				}
+			} else {
+				unparsed.Add(it.Value())
+			}
+		}
+
+		if unparsed.Empty() {
+			return
+		}
+
+		for result := range ts.parseFiles(cfg, args, unparsed) {
+			if !yield(result) {
+				return
+			}
+		}
+	}
+}
+
 func (ts *typeScriptLang) collectImports(cfg *JsGazelleConfig, parserCache cache.Cache, rootDir, sourcePath string) parseResult {
 	parseResults, err := parseSourceFile(parserCache, rootDir, sourcePath)
 
@@ -821,6 +1363,8 @@ func (ts *typeScriptLang) collectImports(cfg *JsGazelleConfig, parserCache cache
 		Error:      err,
 		Imports:    make([]ImportStatement, 0, len(parseResults.Imports)),
 		Modules:    parseResults.Modules,
+		Exports:    parseResults.Exports,
+		ReExports:  parseResults.ReExports,
 	}
 
 	for _, importPath := range parseResults.Imports {
@@ -832,6 +1376,12 @@ func (ts *typeScriptLang) collectImports(cfg *JsGazelleConfig, parserCache cache
 		// The path from the root
 		workspacePath := toImportSpecPath(sourcePath, importPath)
 
+		// The imported names when narrowing barrel imports, otherwise the whole module
+		var names []string
+		if cfg.narrowBarrelImports {
+			names = parseResults.ImportedNames[importPath]
+		}
+
 		// Record all imports. Maybe local, maybe data, maybe in other BUILD etc.
 		result.Imports = append(result.Imports, ImportStatement{
 			ImportSpec: resolve.ImportSpec{
@@ -840,14 +1390,137 @@ func (ts *typeScriptLang) collectImports(cfg *JsGazelleConfig, parserCache cache
 			},
 			ImportPath: importPath,
 			SourcePath: sourcePath,
+			Names:      names,
 		})
 
 		BazelLog.Tracef("%q (%s) imports %q (via %q)", sourcePath, LanguageName, workspacePath, importPath)
 	}
 
+	// Files imported via globs such as import.meta.glob() or require.context()
+	for _, globImport := range expandImportGlobs(sourcePath, parseResults.Globs) {
+		if cfg.IsImportIgnored(globImport.ImportPath) {
+			BazelLog.Tracef("%q (%s) import of %q ignored", sourcePath, LanguageName, globImport.ImportPath)
+			continue
+		}
+
+		result.Imports = append(result.Imports, globImport)
+
+		BazelLog.Tracef("%q (%s) imports %q (via glob %q)", sourcePath, LanguageName, globImport.Imp, globImport.ImportPath)
+	}
+
 	return result
 }
 
+// Expand the import globs of a source file to imports of the matching files.
+//
+// The matching files exist so failing to resolve them is not an error, they may
+// be files such as documentation not owned by any target.
+func expandImportGlobs(sourcePath string, globs []parser.ImportGlob) []ImportStatement {
+	if len(globs) == 0 {
+		return nil
+	}
+
+	sourceDir := path.Dir(sourcePath)
+
+	// Negated patterns exclude files from all globs of the file
+	var excludes []common.GlobExpr
+	for _, g := range globs {
+		if negated, isNegated := strings.CutPrefix(g.Pattern, "!"); isNegated && g.Dir == "" {
+			if expr, err := common.ParseGlobExpression(path.Join(sourceDir, negated)); err == nil {
+				excludes = append(excludes, expr)
+			}
+		}
+	}
+
+	imports := []ImportStatement{}
+
+	for _, g := range globs {
+		if strings.HasPrefix(g.Pattern, "!") {
+			continue
+		}
+
+		// Only relative globs can be expanded, not aliases or absolute paths
+		if g.Dir == "" && !strings.HasPrefix(g.Pattern, ".") {
+			BazelLog.Debugf("%q (%s) import glob %q not relative, ignoring", sourcePath, LanguageName, g.Pattern)
+			continue
+		}
+
+		for _, f := range expandImportGlob(sourceDir, g) {
+			if f == sourcePath || slices.ContainsFunc(excludes, func(exclude common.GlobExpr) bool { return exclude(f) }) {
+				continue
+			}
+
+			imports = append(imports, ImportStatement{
+				ImportSpec: resolve.ImportSpec{
+					Lang: LanguageName,
+					Imp:  toJsFile(f),
+				},
+				ImportPath: path.Join(g.Dir, g.Pattern),
+				SourcePath: sourcePath,
+				Optional:   true,
+			})
+		}
+	}
+
+	return imports
+}
+
+// Expand an import glob relative to the directory to the matching workspace files.
+func expandImportGlob(sourceDir string, g parser.ImportGlob) []string {
+	pattern := path.Join(sourceDir, g.Dir, g.Pattern)
+
+	// The directory to search, the parent directories of the pattern not containing any glob characters
+	baseDir := path.Dir(pattern)
+	for strings.ContainsAny(baseDir, "*?[{") {
+		baseDir = path.Dir(baseDir)
+	}
+
+	// The remaining pattern within the directory
+	subPattern := pattern
+	if baseDir == "." {
+		baseDir = ""
+	} else {
+		subPattern = pattern[len(baseDir)+1:]
+	}
+
+	glob, err := common.ParseGlobExpression(pattern)
+	if err != nil {
+		BazelLog.Warnf("Invalid import glob %q: %v", pattern, err)
+		return nil
+	}
+
+	var filter *regexp.Regexp
+	if g.Filter != "" {
+		filter, err = regexp.Compile(g.Filter)
+		if err != nil {
+			BazelLog.Warnf("Unsupported require.context() filter /%s/: %v", g.Filter, err)
+		}
+	}
+
+	files, err := common.GetAllRegularFiles(baseDir, strings.Contains(subPattern, "/"))
+	if err != nil {
+		BazelLog.Debugf("Failed to expand import glob %q: %v", pattern, err)
+		return nil
+	}
+
+	matches := []string{}
+	for _, f := range files {
+		f = path.Join(baseDir, f)
+		if !glob(f) {
+			continue
+		}
+
+		// require.context() filters are applied to the path relative to the directory
+		if filter != nil && !filter.MatchString("./"+strings.TrimPrefix(f, path.Join(sourceDir, g.Dir)+"/")) {
+			continue
+		}
+
+		matches = append(matches, f)
+	}
+
+	return matches
+}
+
 // Parse the passed file for import statements.
 func parseSourceFile(parserCache cache.Cache, rootDir, filePath string) (parser.ParseResult, error) {
 	BazelLog.Tracef("ParseImports(%s): %s", LanguageName, filePath)
@@ -889,11 +1562,54 @@ func (ts *typeScriptLang) addFileLabel(importPath string, label *label.Label) {
 func (ts *typeScriptLang) addModuleDeclaration(module string, moduleLabel *label.Label) {
 	if ts.moduleTypes[module] == nil {
 		ts.moduleTypes[module] = make([]*label.Label, 0, 1)
+		ts.addModuleDeclarationPattern(module)
 	}
 
 	ts.moduleTypes[module] = append(ts.moduleTypes[module], moduleLabel)
 }
 
+// Add a matcher for a wildcard module declaration such as `declare module "*.svg"`,
+// if the module is a wildcard pattern.
+func (ts *typeScriptLang) addModuleDeclarationPattern(module string) {
+	if ts.moduleTypePatterns[module] != nil {
+		return
+	}
+
+	prefix, suffix, isPattern := strings.Cut(module, "*")
+	if !isPattern {
+		return
+	}
+
+	// TypeScript only supports a single wildcard
+	if strings.Contains(suffix, "*") {
+		BazelLog.Debugf("Unsupported module declaration pattern %q, only a single '*' is supported", module)
+		return
+	}
+
+	// The wildcard matches any characters including "/", unlike the "*" of globs
+	minLen := len(prefix) + len(suffix)
+	ts.moduleTypePatterns[module] = func(p string) bool {
+		return len(p) >= minLen && strings.HasPrefix(p, prefix) && strings.HasSuffix(p, suffix)
+	}
+}
+
+// Find the wildcard module declaration matching an import, the pattern with the
+// longest prefix taking precedence as in TypeScript.
+func (ts *typeScriptLang) findModuleDeclarationPattern(importPath string) string {
+	match, matchPrefixLen := "", -1
+	for pattern, matcher := range ts.moduleTypePatterns {
+		if !matcher(importPath) {
+			continue
+		}
+
+		prefixLen := strings.IndexByte(pattern, '*')
+		if prefixLen > matchPrefixLen || (prefixLen == matchPrefixLen && pattern < match) {
+			match, matchPrefixLen = pattern, prefixLen
+		}
+	}
+	return match
+}
+
 // Find names/paths that the given path can be imported as.
 func toImportPaths(p string) []string {
 	// NOTE: this is invoked extremely frequently so it's important to keep it fast and light.
@@ -937,6 +1653,9 @@ func toImportPaths(p string) []string {
 		if strings.HasSuffix(pNoExt, SlashIndexFileName) {
 			paths = append(paths, pNoExt[:len(pNoExt)-len(SlashIndexFileName)])
 		}
+	} else if parser.IsComponentFileExt(pExt) {
+		// Components are only imported with the extension
+		paths = append(paths, p)
 	} else if isSourceFileExt(pExt) {
 		// The import of the raw file
 		paths = append(paths, p)
@@ -958,7 +1677,7 @@ func toImportPaths(p string) []string {
 }
 
 // Collect and persist all possible references to files that can be imported
-func (ts *typeScriptLang) collectFileLabels(args language.GenerateArgs) {
+func (ts *typeScriptLang) collectFileLabels(cfg *JsGazelleConfig, args language.GenerateArgs) {
 	// Generated files from rules such as genrule()
 	for _, f := range args.GenFiles {
 		// Label referencing that generated file
@@ -971,6 +1690,21 @@ func (ts *typeScriptLang) collectFileLabels(args language.GenerateArgs) {
 		for _, importPath := range toImportPaths(path.Join(args.Rel, f)) {
 			ts.addFileLabel(importPath, &genLabel)
 		}
+
+		if cfg.IsAssetFile(f) {
+			ts.addFileLabel(path.Join(args.Rel, f), &genLabel)
+		}
+	}
+
+	// Assets which may be imported without being a part of any target
+	for _, f := range args.RegularFiles {
+		if cfg.IsAssetFile(f) {
+			ts.addFileLabel(path.Join(args.Rel, f), &label.Label{
+				Name: f,
+				Repo: args.Config.RepoName,
+				Pkg:  args.Rel,
+			})
+		}
 	}
 
 	// TODO(jbedard): record other generated non-source files (args.OtherGen, ?)
@@ -980,6 +1714,7 @@ func (ts *typeScriptLang) collectFileLabels(args language.GenerateArgs) {
 func (ts *typeScriptLang) addPackageRules(cfg *JsGazelleConfig, args language.GenerateArgs, result *language.GenerateResult) {
 	if ts.pnpmProjects.IsProject(args.Rel) {
 		addLinkAllPackagesRule(cfg, args, ts.pnpmProjects.GetProject(args.Rel), result)
+		ts.addNpmDependencyValidation(args.Config, args.Rel)
 	}
 }
 
@@ -989,8 +1724,15 @@ func (ts *typeScriptLang) addPnpmLockfile(c *config.Config, cfg *JsGazelleConfig
 	BazelLog.Infof("pnpm add %q", lockfileRel)
 
 	parsedCache := cache.Get(c)
-	parsedLockfile, _, readErr := parsedCache.LoadOrStoreFile(c.RepoRoot, lockfileRel, "pnpm.ParsePnpmLockFile", func(filePath string, content []byte) (any, error) {
-		return pnpm.ParsePnpmLockFileDependencies(content)
+	lockfile := pnpm.GetLockfile(lockfileRel)
+	lockfileKey, keyErr := computeLockfileCacheKey(lockfile, path.Join(c.RepoRoot, lockfileRel))
+	if keyErr != nil {
+		common.MisconfiguredErrorf(c, "failed to read lockfile %q workspace: %v", lockfileRel, keyErr)
+		return
+	}
+
+	parsedLockfile, _, readErr := parsedCache.LoadOrStoreFile(c.RepoRoot, lockfileRel, lockfileKey, func(filePath string, content []byte) (any, error) {
+		return lockfile.ParseDependencies(path.Join(c.RepoRoot, path.Dir(filePath)), content)
 	})
 	if readErr != nil {
 		common.MisconfiguredErrorf(c, "failed to read lockfile %q: %v", lockfileRel, readErr)
@@ -1017,6 +1759,40 @@ func (ts *typeScriptLang) addPnpmLockfile(c *config.Config, cfg *JsGazelleConfig
 	}
 }
 
+// The cache key of the parsed lockfile, including the content of workspace files
+// read in addition to the lockfile.
+func computeLockfileCacheKey(lockfile pnpm.Lockfile, lockfilePath string) (string, error) {
+	workspaceLockfile, readsWorkspaceFiles := lockfile.(pnpm.WorkspaceFilesLockfile)
+	if !readsWorkspaceFiles {
+		return "pnpm.ParseLockFile", nil
+	}
+
+	lockfileContent, err := os.ReadFile(lockfilePath)
+	if err != nil {
+		return "", err
+	}
+
+	files, err := workspaceLockfile.WorkspaceFiles(path.Dir(lockfilePath), lockfileContent)
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return "pnpm.ParseLockFile", nil
+	}
+
+	cacheDigest := md5.New()
+	for _, f := range files {
+		content, err := os.ReadFile(f)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(cacheDigest, "%s\x00%d\x00", f, len(content))
+		cacheDigest.Write(content)
+	}
+
+	return "pnpm.ParseLockFile|" + hex.EncodeToString(cacheDigest.Sum(nil)), nil
+}
+
 func addLinkAllPackagesRule(cfg *JsGazelleConfig, args language.GenerateArgs, pnpmProject *pnpm.PnpmProject, result *language.GenerateResult) {
 	npmLinkAll := rule.NewRule(NpmLinkAllKind, cfg.npmLinkAllTargetName)
 
@@ -1092,6 +1868,16 @@ func isDeclarationFileType(f string) bool {
 	return strings.HasSuffix(f, ".d.ts") || strings.HasSuffix(f, ".d.mts") || strings.HasSuffix(f, ".d.cts")
 }
 
+// Stylesheet file extensions which may import other assets.
+func isStylesheetFileExt(e string) bool {
+	switch e {
+	case ".css", ".scss", ".sass", ".less":
+		return true
+	default:
+		return false
+	}
+}
+
 // Supported data file extensions that typescript can reference.
 func isDataFileExt(e string) bool {
 	return e == ".json"
@@ -1115,6 +1901,16 @@ func toJsExt(e string) string {
 	}
 }
 
+// The js file a source file is transpiled to, or the file itself if not transpiled.
+func toJsFile(f string) string {
+	if !isTranspiledSourceFileType(f) {
+		return f
+	}
+
+	ext := path.Ext(f)
+	return f[:len(f)-len(ext)] + toJsExt(ext)
+}
+
 func toDtsExt(e string) string {
 	switch e {
 	case ".ts", ".tsx":
@@ -1147,6 +1943,81 @@ func toImportSpecPath(importFrom, importPath string) string {
 	return path.Clean(importPath)
 }
 
+// Normalize a stylesheet @import or url() to a path relative to the workspace.
+//
+// Stylesheet urls are relative to the stylesheet unless prefixed with "~" to reference
+// a package. Bare urls not found relative to the stylesheet, such as "normalize.css",
+// are assumed to be packages.
+func toStylesheetImportSpecPath(rootDir, stylesheet, importPath string) string {
+	if pkgPath, isPkg := strings.CutPrefix(importPath, "~"); isPkg {
+		return path.Clean(pkgPath)
+	}
+
+	relPath := importPath
+	if relPath[0] != '.' {
+		relPath = "./" + relPath
+	}
+	workspacePath := toImportSpecPath(stylesheet, relPath)
+
+	for _, p := range toStylesheetImportCandidates(path.Ext(stylesheet), workspacePath) {
+		if _, err := os.Stat(path.Join(rootDir, p)); err == nil {
+			return p
+		}
+	}
+
+	if importPath[0] != '.' {
+		return path.Clean(importPath)
+	}
+
+	return workspacePath
+}
+
+// The possible files of a stylesheet import, including the partials and implicit
+// extensions of Sass and LESS imports.
+func toStylesheetImportCandidates(stylesheetExt, p string) []string {
+	ext := path.Ext(p)
+	if isStylesheetFileExt(ext) {
+		return []string{p}
+	}
+
+	// Files with other extensions such as url(logo.svg)
+	candidates := make([]string, 0, 8)
+	if ext != "" {
+		candidates = append(candidates, p)
+	}
+
+	dir, base := path.Split(p)
+
+	switch stylesheetExt {
+	case ".scss", ".sass":
+		candidates = append(candidates,
+			p+".scss",
+			dir+"_"+base+".scss",
+			p+".sass",
+			dir+"_"+base+".sass",
+			p+".css",
+			p+"/_index.scss",
+			p+"/index.scss",
+		)
+	case ".less":
+		candidates = append(candidates, p+".less")
+	default:
+		if ext == "" {
+			candidates = append(candidates, p)
+		}
+	}
+
+	return candidates
+}
+
+// The path of a workspace path relative to the package, if within the package.
+func toPackagePath(rel, workspacePath string) (string, bool) {
+	if rel == "" {
+		return workspacePath, !strings.HasPrefix(workspacePath, "../")
+	}
+	return strings.CutPrefix(workspacePath, rel+"/")
+}
+
 // Return the default target name for the given language.GenerateArgs.
 // The default target name of a BUILD is the directory name. WHen within the repository
 // root which may be outside of version control the default target name is the repository name.
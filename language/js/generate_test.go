@@ -4,8 +4,12 @@ import (
 	"fmt"
 	"path"
 	"reflect"
+	"regexp"
 	"slices"
 	"testing"
+
+	"github.com/aspect-build/aspect-gazelle/language/js/parser"
+	"github.com/bazelbuild/bazel-gazelle/label"
 )
 
 func TestGenerate(t *testing.T) {
@@ -154,6 +158,274 @@ func TestGenerate(t *testing.T) {
 		assertImports(t, "bar/index.cts", []string{"bar/index.cjs", "bar/index.d.cts", "bar"})
 		assertImports(t, "bar.d.cts", []string{"bar.d.cts", "bar.cjs"})
 	})
+
+	t.Run("toStylesheetImportCandidates", func(t *testing.T) {
+		// Explicit stylesheet and asset files
+		assertStylesheetImports(t, ".css", "a/b.css", []string{"a/b.css"})
+		assertStylesheetImports(t, ".scss", "a/b.css", []string{"a/b.css"})
+		assertStylesheetImports(t, ".css", "a/logo.svg", []string{"a/logo.svg"})
+
+		// Sass partials and implicit extensions
+		assertStylesheetImports(t, ".scss", "a/b", []string{"a/b.scss", "a/_b.scss", "a/b.sass", "a/_b.sass", "a/b.css", "a/b/_index.scss", "a/b/index.scss"})
+		assertStylesheetImports(t, ".scss", "a/logo.svg", []string{"a/logo.svg", "a/logo.svg.scss", "a/_logo.svg.scss", "a/logo.svg.sass", "a/_logo.svg.sass", "a/logo.svg.css", "a/logo.svg/_index.scss", "a/logo.svg/index.scss"})
+
+		// LESS implicit extensions
+		assertStylesheetImports(t, ".less", "a/b", []string{"a/b.less"})
+	})
+
+	t.Run("testRunnerConfig.mapImport", func(t *testing.T) {
+		trc := &testRunnerConfig{runner: TestRunnerJest, configFile: "app/jest.config.js"}
+		mappings := []moduleNameMapping{
+			{pattern: regexp.MustCompile(`^@/(.*)$`), target: "<rootDir>/src/$1"},
+			{pattern: regexp.MustCompile(`\.css$`), target: "identity-obj-proxy"},
+			{pattern: regexp.MustCompile(`^~(.*)$`), target: "../shared$1"},
+		}
+
+		// Paths relative to the jest <rootDir> or config directory
+		assertMappedImport(t, trc, mappings, "@/a/b", "app/src/a/b")
+		assertMappedImport(t, trc, mappings, "~/c", "shared/c")
+
+		// Packages
+		assertMappedImport(t, trc, mappings, "./styles.css", "identity-obj-proxy")
+
+		// Imports not matching any mapping
+		assertMappedImport(t, trc, mappings, "lodash", "lodash")
+	})
+
+	t.Run("toNpmSpecifier", func(t *testing.T) {
+		for specifier, expected := range map[string]string{
+			"npm:chalk":                   "chalk",
+			"npm:chalk@5.3.0":             "chalk",
+			"npm:/chalk@^5":               "chalk",
+			"npm:lodash-es@4/debounce":    "lodash-es/debounce",
+			"npm:@scope/pkg":              "@scope/pkg",
+			"npm:@scope/pkg@1.0.0/a/b.js": "@scope/pkg/a/b.js",
+		} {
+			if actual := toNpmSpecifier(specifier); actual != expected {
+				t.Errorf("toNpmSpecifier('%s'): \nactual:   %s\nexpected:  %s\n", specifier, actual, expected)
+			}
+		}
+	})
+
+	t.Run("ImportMap.Resolve", func(t *testing.T) {
+		importMap := ImportMap{
+			Imports: map[string]string{
+				"lit":       "https://esm.sh/lit@3",
+				"lit/":      "https://esm.sh/lit@3/",
+				"chalk":     "npm:chalk@5",
+				"app/":      "./src/app/",
+				"app/utils": "/lib/utils.ts",
+			},
+			Scopes: map[string]map[string]string{
+				"./legacy/":     {"app/": "./src/app-v1/"},
+				"./legacy/new/": {"lit": "./vendor/lit.js"},
+			},
+		}
+
+		assertImportMap(t, importMap, "web/main.ts", "lit", "https://esm.sh/lit@3")
+		assertImportMap(t, importMap, "web/main.ts", "lit/decorators.js", "https://esm.sh/lit@3/decorators.js")
+		assertImportMap(t, importMap, "web/main.ts", "chalk", "chalk")
+		assertImportMap(t, importMap, "web/main.ts", "app/store", "web/src/app/store")
+		assertImportMap(t, importMap, "web/main.ts", "app/utils", "web/lib/utils.ts")
+
+		// Scopes, the most specific scope taking precedence
+		assertImportMap(t, importMap, "web/legacy/main.ts", "app/store", "web/src/app-v1/store")
+		assertImportMap(t, importMap, "web/legacy/new/main.ts", "lit", "web/vendor/lit.js")
+		assertImportMap(t, importMap, "web/legacy/new/main.ts", "app/store", "web/src/app-v1/store")
+		assertImportMap(t, importMap, "web/other/main.ts", "lit", "https://esm.sh/lit@3")
+
+		// Not mapped
+		assertImportMap(t, importMap, "web/main.ts", "react", "")
+		assertImportMap(t, importMap, "web/main.ts", "application", "")
+	})
+
+	t.Run("findExportingModule", func(t *testing.T) {
+		ts := NewLanguage().(*typeScriptLang)
+		ts.addModuleExports("ui/index.ts", []string{"VERSION"}, []parser.ReExport{
+			{From: "./button", Name: "*", Imported: "*"},
+			{From: "./card", Name: "Card", Imported: "Card"},
+			{From: "./icon", Name: "Icon", Imported: "default"},
+			{From: "./theme", Name: "theme", Imported: "*"},
+			{From: "lodash", Name: "debounce", Imported: "debounce"},
+		}, true)
+		ts.addModuleExports("ui/button/index.ts", []string{"Button"}, nil, true)
+		ts.addModuleExports("ui/card.ts", []string{"Card"}, nil, true)
+		ts.addModuleExports("ui/icon.tsx", []string{"default"}, nil, true)
+		ts.addModuleExports("ui/theme.ts", []string{"dark"}, nil, true)
+
+		assertExportingModule(t, ts, "ui", "Button", "ui/button/index.ts")
+		assertExportingModule(t, ts, "ui/index.js", "Card", "ui/card.ts")
+		assertExportingModule(t, ts, "ui", "Icon", "ui/icon.tsx")
+		assertExportingModule(t, ts, "ui", "theme", "ui/theme.ts")
+
+		// Names of the barrel itself or re-exported from packages
+		assertExportingModule(t, ts, "ui", "VERSION", "ui/index.ts")
+		assertExportingModule(t, ts, "ui", "debounce", "ui/index.ts")
+
+		// Unknown names, `export *` never re-exports the default export
+		assertExportingModule(t, ts, "ui", "Unknown", "")
+		assertExportingModule(t, ts, "ui", "default", "")
+	})
+
+	t.Run("findModuleDeclarationPattern", func(t *testing.T) {
+		ts := NewLanguage().(*typeScriptLang)
+		for _, module := range []string{"*.svg", "*?raw", "virtual:*", "virtual:pwa/*", "a*b*c", "exact"} {
+			ts.addModuleDeclaration(module, &label.Label{Name: "types"})
+		}
+
+		for importPath, expected := range map[string]string{
+			"./logo.svg":           "*.svg",
+			"../assets/a/logo.svg": "*.svg",
+			".svg":                 "*.svg",
+			"./a.txt?raw":          "*?raw",
+			"virtual:config":       "virtual:*",
+			"virtual:pwa/register": "virtual:pwa/*",
+			"./logo.png":           "",
+			"exact":                "",
+			"abc":                  "",
+		} {
+			if actual := ts.findModuleDeclarationPattern(importPath); actual != expected {
+				t.Errorf("findModuleDeclarationPattern('%s'): \nactual:   %s\nexpected:  %s\n", importPath, actual, expected)
+			}
+		}
+	})
+
+	t.Run("TsProjectInfo.AddImport names", func(t *testing.T) {
+		info := newTsProjectInfo()
+		addNamedImport(info, "a", []string{"b", "a"})
+		addNamedImport(info, "a", []string{"c", "a"})
+		addNamedImport(info, "b", []string{"b"})
+		addNamedImport(info, "b", nil)
+		addNamedImport(info, "b", []string{"c"})
+
+		for imp, expected := range map[string][]string{"a": {"a", "b", "c"}, "b": nil} {
+			_, actual := info.imports.Find(func(_ int, v interface{}) bool { return v.(ImportStatement).Imp == imp })
+			if actualNames := actual.(ImportStatement).Names; !reflect.DeepEqual(actualNames, expected) {
+				t.Errorf("AddImport('%s') names: \nactual:   %v\nexpected:  %v\n", imp, actualNames, expected)
+			}
+		}
+	})
+
+	t.Run("toStronglyConnectedComponents", func(t *testing.T) {
+		actual := toStronglyConnectedComponents(map[string][]string{
+			"a.ts":     {"b.ts"},
+			"b.ts":     {"c.ts", "d.ts"},
+			"c.ts":     {"b.ts"},
+			"d.ts":     nil,
+			"e.ts":     {"f.ts", "f.ts"},
+			"f.ts":     {"g.ts"},
+			"g.ts":     {"e.ts"},
+			"x/h.ts":   {"a.ts"},
+			"x/h.d.ts": nil,
+		})
+		expected := [][]string{{"a.ts"}, {"b.ts", "c.ts"}, {"d.ts"}, {"e.ts", "f.ts", "g.ts"}, {"x/h.d.ts"}, {"x/h.ts"}}
+
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("toStronglyConnectedComponents: \nactual:   %v\nexpected:  %v\n", actual, expected)
+		}
+	})
+
+	t.Run("findImportCycle", func(t *testing.T) {
+		graph := map[string][]string{
+			"//a": {"//b", "//d"},
+			"//b": {"//c"},
+			"//c": {"//a", "//b"},
+			"//d": {"//c"},
+		}
+
+		for _, tc := range []struct {
+			component, expected []string
+		}{
+			{[]string{"//a", "//b", "//c", "//d"}, []string{"//a", "//b", "//c"}},
+			{[]string{"//b", "//c"}, []string{"//b", "//c"}},
+			{[]string{"//d", "//c", "//a"}, []string{"//d", "//c", "//a"}},
+		} {
+			if actual := findImportCycle(graph, tc.component); !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("findImportCycle(%v): \nactual:   %v\nexpected:  %v\n", tc.component, actual, tc.expected)
+			}
+		}
+	})
+
+	t.Run("toFileTargetName", func(t *testing.T) {
+		names := map[string]bool{"lib": true, "lib_tests": true}
+		for file, expected := range map[string]string{
+			"a.ts":          "a",
+			"sub/b.test.ts": "sub/b.test",
+			"types.d.ts":    "types",
+			"lib.ts":        "lib_ts",
+			"lib_tests.ts":  "lib_tests_ts",
+		} {
+			if actual := toFileTargetName(file, names); actual != expected {
+				t.Errorf("toFileTargetName('%s'): \nactual:   %s\nexpected:  %s\n", file, actual, expected)
+			}
+		}
+	})
+
+	t.Run("parseNpmIgnore", func(t *testing.T) {
+		actual := parseNpmIgnore([]byte("# comment\n\n*.log\n/scripts/\nsrc/*.test.js\n!keep.log\n"))
+		expected := []string{"**/*.log", "**/*.log/**", "scripts", "scripts/**", "src/*.test.js", "src/*.test.js/**"}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("parseNpmIgnore: \nactual:   %s\nexpected:  %s\n", actual, expected)
+		}
+	})
+
+	t.Run("isNpmPackAlwaysPublished", func(t *testing.T) {
+		for file, expected := range map[string]bool{
+			"package.json":     true,
+			"README.md":        true,
+			"readme":           true,
+			"LICENSE":          true,
+			"Licence.txt":      true,
+			"docs/README.md":   false,
+			"lib/package.json": false,
+			"CHANGELOG.md":     false,
+		} {
+			if actual := isNpmPackAlwaysPublished(file); actual != expected {
+				t.Errorf("isNpmPackAlwaysPublished('%s'): \nactual:   %v\nexpected:  %v\n", file, actual, expected)
+			}
+		}
+	})
+}
+
+func addNamedImport(info *TsProjectInfo, imp string, names []string) {
+	impStm := ImportStatement{ImportPath: imp, Names: names}
+	impStm.Imp = imp
+	info.AddImport(impStm)
+}
+
+func assertExportingModule(t *testing.T, ts *typeScriptLang, imp, name, expected string) {
+	actual := ""
+	if exporting := ts.findExportingModule(ts.moduleExports[imp], name, map[string]bool{}); exporting != nil {
+		actual = exporting.file
+	}
+	if actual != expected {
+		t.Errorf("findExportingModule('%s', '%s'): \nactual:   %s\nexpected:  %s\n", imp, name, actual, expected)
+	}
+}
+
+func assertImportMap(t *testing.T, importMap ImportMap, from, specifier, expected string) {
+	actual, _ := importMap.Resolve("web", from, specifier)
+	if actual != expected {
+		t.Errorf("ImportMap.Resolve('%s', '%s'): \nactual:   %s\nexpected:  %s\n", from, specifier, actual, expected)
+	}
+}
+
+func assertMappedImport(t *testing.T, trc *testRunnerConfig, mappings []moduleNameMapping, importPath, expected string) {
+	imp := ImportStatement{ImportPath: importPath}
+	imp.Imp = importPath
+
+	actual := trc.mapImport(mappings, imp).Imp
+	if actual != expected {
+		t.Errorf("mapImport('%s'): \nactual:   %s\nexpected:  %s\n", importPath, actual, expected)
+	}
+}
+
+func assertStylesheetImports(t *testing.T, stylesheetExt, p string, expected []string) {
+	actual := toStylesheetImportCandidates(stylesheetExt, p)
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("toStylesheetImportCandidates('%s', '%s'): \nactual:   %s\nexpected:  %s\n", stylesheetExt, p, actual, expected)
+	}
 }
 
 func assertImports(t *testing.T, p string, expected []string) {
@@ -0,0 +1,206 @@
+package gazelle
+
+import (
+	"path"
+	"slices"
+	"strings"
+
+	BazelLog "github.com/aspect-build/aspect-gazelle/common/logger"
+	ruleUtils "github.com/aspect-build/aspect-gazelle/common/rule"
+	"github.com/aspect-build/aspect-gazelle/language/js/typescript"
+	"github.com/bazelbuild/bazel-gazelle/language"
+	"github.com/bazelbuild/bazel-gazelle/resolve"
+	"github.com/bazelbuild/bazel-gazelle/rule"
+	"github.com/emirpasic/gods/sets/treeset"
+)
+
+// File granularity generates a target per source file of a target group. Files
+// importing each other in a cycle are merged into a single target, Bazel targets
+// can not depend on each other.
+
+// Add a rule per source file of a target group, or per cycle of files importing each other.
+//
+// Library groups also have a rule of the group target name depending on all the
+// file rules. Returns that library rule, or nil for test groups.
+//
+// The targetNames are the names of all targets generated in the package, file target
+// names are added as they are generated.
+func (ts *typeScriptLang) addFileRules(cfg *JsGazelleConfig, tsconfigRel string, tsconfig *typescript.TsConfig, args language.GenerateArgs, group *TargetGroup, targetName string, sourceFiles, genFiles, dataFiles, assetFiles *treeset.Set, targetNames map[string]bool, result *language.GenerateResult) (*rule.Rule, error) {
+	groupFiles := treeset.NewWithStringComparator(sourceFiles.Values()...)
+	if genFiles != nil {
+		groupFiles.Add(genFiles.Values()...)
+	}
+
+	imports, parsed, err := ts.collectFileImports(cfg, args, sourceFiles, groupFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, component := range toStronglyConnectedComponents(imports) {
+		name := toFileTargetName(component[0], targetNames)
+		targetNames[name] = true
+
+		if len(component) > 1 {
+			BazelLog.Infof("merging cyclic imports of %v into '%s:%s'", component, args.Rel, name)
+		}
+
+		componentSrcs := treeset.NewWithStringComparator()
+		componentGenSrcs := treeset.NewWithStringComparator()
+		for _, f := range component {
+			if sourceFiles.Contains(f) {
+				componentSrcs.Add(f)
+			} else {
+				componentGenSrcs.Add(f)
+			}
+		}
+
+		fileRule, err := ts.addProjectRule(cfg, tsconfigRel, tsconfig, args, group, name, componentSrcs, componentGenSrcs, dataFiles, assetFiles, parsed, result)
+		if err != nil {
+			return nil, err
+		}
+
+		if group.testonly && cfg.getTestRunner() != nil {
+			ts.addTestRunnerRule(cfg, args, fileRule, result)
+		}
+	}
+
+	removeStaleFileRules(args, group, groupFiles, targetNames, result)
+
+	if group.testonly {
+		ruleUtils.RemoveRule(args, targetName, sourceRuleKinds, result)
+		return nil, nil
+	}
+
+	return ts.addFileLibraryRule(cfg, args, group, targetName, groupFiles, result), nil
+}
+
+// Add the library rule of a target group depending on the rules of all files of the group.
+//
+// The dependencies are resolved as imports of each file of the group.
+func (ts *typeScriptLang) addFileLibraryRule(cfg *JsGazelleConfig, args language.GenerateArgs, group *TargetGroup, targetName string, groupFiles *treeset.Set, result *language.GenerateResult) *rule.Rule {
+	info := newTsProjectInfo()
+	for it := groupFiles.Iterator(); it.Next(); {
+		file := it.Value().(string)
+		info.AddImport(ImportStatement{
+			ImportSpec: resolve.ImportSpec{
+				Lang: LanguageName,
+				Imp:  toImportPaths(path.Join(args.Rel, file))[0],
+			},
+			ImportPath: "./" + file,
+			SourcePath: path.Join(args.Rel, file),
+		})
+	}
+
+	existing := ruleUtils.GetFileRuleByName(args, targetName)
+	if existing != nil && existing.Kind() != JsLibraryKind {
+		existing.SetKind(JsLibraryKind)
+	}
+
+	libraryRule := rule.NewRule(JsLibraryKind, targetName)
+	libraryRule.SetPrivateAttr("ts_project_info", info)
+
+	if len(group.visibility) > 0 {
+		libraryRule.SetAttr("visibility", group.visibility)
+	}
+
+	result.Gen = append(result.Gen, libraryRule)
+	result.Imports = append(result.Imports, info)
+	result.RelsToIndex = append(result.RelsToIndex, ts.tsPackageInfoToRelsToIndex(cfg, args, info)...)
+
+	BazelLog.Infof("add rule '%s' '%s:%s'", libraryRule.Kind(), args.Rel, libraryRule.Name())
+
+	return libraryRule
+}
+
+// Collect the files of the group imported by each file of the group, and the parse
+// results of the source files by file.
+func (ts *typeScriptLang) collectFileImports(cfg *JsGazelleConfig, args language.GenerateArgs, sourceFiles, groupFiles *treeset.Set) (map[string][]string, map[string]parseResult, error) {
+	// Import path => file of the group
+	importedFiles := make(map[string]string, groupFiles.Size())
+	imports := make(map[string][]string, groupFiles.Size())
+	for it := groupFiles.Iterator(); it.Next(); {
+		file := it.Value().(string)
+		for _, importPath := range toImportPaths(path.Join(args.Rel, file)) {
+			importedFiles[importPath] = file
+		}
+		imports[file] = nil
+	}
+
+	// Generated files are not parsed and have no imports.
+	parsed := make(map[string]parseResult, sourceFiles.Size())
+	for result := range ts.parseFiles(cfg, args, sourceFiles) {
+		if result.Error != nil {
+			return nil, nil, result.Error
+		}
+
+		file := result.SourcePath[len(args.Rel):]
+		if args.Rel != "" {
+			file = file[1:]
+		}
+		parsed[file] = result
+
+		for _, imp := range result.Imports {
+			candidates := append([]string{imp.Imp}, ts.tsconfig.ExpandPaths(imp.SourcePath, imp.Imp)...)
+			for _, candidate := range candidates {
+				if imported, found := importedFiles[candidate]; found && imported != file {
+					imports[file] = append(imports[file], imported)
+					break
+				}
+			}
+		}
+	}
+
+	return imports, parsed, nil
+}
+
+// The target name of a file such as "foo" for "foo.ts", or "foo_ts" if the name
+// without the extension is already used.
+func toFileTargetName(file string, names map[string]bool) string {
+	name := strings.TrimSuffix(file, path.Ext(file))
+	if isDeclarationFileType(file) {
+		name = strings.TrimSuffix(name, ".d")
+	}
+
+	if names[name] {
+		name = strings.ReplaceAll(file, ".", "_")
+	}
+
+	return name
+}
+
+// Remove rules of files of the group from a previous run that are no longer generated,
+// such as the rule of a file now merged with other files importing it in a cycle.
+func removeStaleFileRules(args language.GenerateArgs, group *TargetGroup, groupFiles *treeset.Set, names map[string]bool, result *language.GenerateResult) {
+	if args.File == nil {
+		return
+	}
+
+	packageFiles := treeset.NewWithStringComparator()
+	for _, f := range args.RegularFiles {
+		packageFiles.Add(f)
+	}
+	for _, f := range args.GenFiles {
+		packageFiles.Add(f)
+	}
+
+	for _, r := range args.File.Rules {
+		if names[r.Name()] || !sourceRuleKinds.Contains(r.Kind()) || isCustomSrcs(r.Attr("srcs")) {
+			continue
+		}
+
+		// Rules of only files of the group or files that no longer exist
+		srcs := r.AttrStrings("srcs")
+		isStale := len(srcs) > 0 && !slices.ContainsFunc(srcs, func(src string) bool {
+			return packageFiles.Contains(src) && !groupFiles.Contains(src)
+		})
+		if !isStale {
+			continue
+		}
+
+		ruleUtils.RemoveRule(args, r.Name(), sourceRuleKinds, result)
+
+		if group.testonly {
+			removeTestRunnerRules(args, r.Name(), result)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package gazelle
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/gob"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/aspect-build/aspect-gazelle/common/cache"
+	"github.com/bazelbuild/bazel-gazelle/config"
+	"github.com/msolo/jsonr"
+)
+
+// An import map such as an importmap.json, or the "imports" and "scopes" of a deno.json.
+//
+// See https://html.spec.whatwg.org/multipage/webappapis.html#import-maps
+// and https://docs.deno.com/runtime/fundamentals/modules/#import-maps
+type ImportMap struct {
+	Imports map[string]string            `json:"imports"`
+	Scopes  map[string]map[string]string `json:"scopes"`
+
+	// The deno.json "importMap" file containing the import map
+	ImportMapFile string `json:"importMap"`
+}
+
+func init() {
+	gob.Register(ImportMap{})
+}
+
+func parseImportMap(content []byte) (ImportMap, error) {
+	var m ImportMap
+	err := jsonr.NewDecoder(bytes.NewReader(content)).Decode(&m)
+	return m, err
+}
+
+// Load the import map of the workspace file, following a deno.json "importMap" to
+// a separate import map file.
+//
+// Returns the import map and the workspace path of the file declaring it.
+func loadImportMap(c *config.Config, importMapFile string) (*ImportMap, string, error) {
+	visited := make(map[string]bool)
+
+	for !visited[importMapFile] {
+		visited[importMapFile] = true
+
+		r, _, err := cache.Get(c).LoadOrStoreFile(c.RepoRoot, importMapFile, "js.ParseImportMap", func(filePath string, content []byte) (any, error) {
+			return parseImportMap(content)
+		})
+		if err != nil {
+			return nil, importMapFile, err
+		}
+
+		m := r.(ImportMap)
+		if m.Imports != nil || m.Scopes != nil || m.ImportMapFile == "" {
+			return &m, importMapFile, nil
+		}
+
+		importMapFile = path.Join(path.Dir(importMapFile), m.ImportMapFile)
+	}
+
+	return nil, importMapFile, fmt.Errorf("circular \"importMap\" reference to %q", importMapFile)
+}
+
+// Map a specifier imported from the workspace file `from` using the import map
+// declared in the workspace directory `mapDir`.
+//
+// Mapped paths are returned as workspace paths, "npm:" specifiers as package
+// specifiers and other specifiers such as URLs are returned as is.
+func (m ImportMap) Resolve(mapDir, from, specifier string) (string, bool) {
+	// The scopes containing the importing file, the most specific first
+	scopes := []string{}
+	for scope := range m.Scopes {
+		scopePath := toImportMapPath(mapDir, scope)
+		if strings.HasSuffix(scope, "/") {
+			scopePath += "/"
+		}
+
+		if from == scopePath || strings.HasPrefix(from, scopePath) {
+			scopes = append(scopes, scope)
+		}
+	}
+	slices.SortFunc(scopes, func(a, b string) int {
+		return cmp.Or(cmp.Compare(len(b), len(a)), strings.Compare(a, b))
+	})
+
+	for _, scope := range scopes {
+		if target, found := resolveImportMapSpecifier(m.Scopes[scope], specifier); found {
+			return toImportMapTarget(mapDir, target), true
+		}
+	}
+
+	if target, found := resolveImportMapSpecifier(m.Imports, specifier); found {
+		return toImportMapTarget(mapDir, target), true
+	}
+
+	return "", false
+}
+
+// Resolve a specifier using an exact match or the longest matching "/" suffixed prefix.
+func resolveImportMapSpecifier(imports map[string]string, specifier string) (string, bool) {
+	if target, found := imports[specifier]; found {
+		return target, true
+	}
+
+	bestPrefix := ""
+	for prefix := range imports {
+		if strings.HasSuffix(prefix, "/") && strings.HasPrefix(specifier, prefix) && len(prefix) > len(bestPrefix) {
+			bestPrefix = prefix
+		}
+	}
+
+	if bestPrefix == "" || !strings.HasSuffix(imports[bestPrefix], "/") {
+		return "", false
+	}
+
+	return imports[bestPrefix] + specifier[len(bestPrefix):], true
+}
+
+func toImportMapTarget(mapDir, target string) string {
+	if strings.HasPrefix(target, "npm:") {
+		return toNpmSpecifier(target)
+	}
+	return toImportMapPath(mapDir, target)
+}
+
+// Paths within an import map are relative to the import map, absolute paths are
+// assumed to be relative to the import map as the root of the server.
+func toImportMapPath(mapDir, p string) string {
+	if strings.HasPrefix(p, "./") || strings.HasPrefix(p, "../") || strings.HasPrefix(p, "/") {
+		return path.Join(mapDir, p)
+	}
+	return p
+}
+
+// Convert a Deno "npm:" specifier such as "npm:@scope/pkg@^1.0.0/sub" to the
+// package specifier "@scope/pkg/sub".
+func toNpmSpecifier(specifier string) string {
+	specifier = strings.TrimPrefix(strings.TrimPrefix(specifier, "npm:"), "/")
+
+	// The scope of scoped packages
+	scope := ""
+	if specifier != "" && specifier[0] == '@' {
+		if scopeEnd := strings.IndexByte(specifier, '/'); scopeEnd != -1 {
+			scope, specifier = specifier[:scopeEnd+1], specifier[scopeEnd+1:]
+		}
+	}
+
+	name, subpath, hasSubpath := strings.Cut(specifier, "/")
+
+	// Strip the version
+	if versionStart := strings.IndexByte(name, '@'); versionStart > 0 {
+		name = name[:versionStart]
+	}
+
+	if hasSubpath {
+		return scope + name + "/" + subpath
+	}
+	return scope + name
+}
+
+// If the import is of a URL such as "https://deno.land/x/mod.ts" or a JSR specifier.
+func isUrlImport(imp string) bool {
+	return strings.Contains(imp, "://") || strings.HasPrefix(imp, "jsr:")
+}
@@ -8,10 +8,14 @@ import (
 const (
 	TsProjectKind         = "ts_project"
 	TsProtoLibraryKind    = "ts_proto_library"
+	JsGrpcWebLibraryKind  = "js_grpc_web_library"
 	JsLibraryKind         = "js_library"
 	JsBinaryKind          = "js_binary"
 	JsRunBinaryKind       = "js_run_binary"
 	JsTestKind		 	  = "js_test"
+	JestTestKind          = "jest_test"
+	VitestTestKind        = "vitest_test"
+	MochaTestKind         = "mocha_test"
 	TsConfigKind          = "ts_config"
 	NpmPackageKind        = "npm_package"
 	NpmLinkAllKind        = "npm_link_all_packages"
@@ -19,10 +23,15 @@ const (
 	RulesJsRepositoryName = RulesJsModuleName
 	RulesTsModuleName     = "aspect_rules_ts"
 	RulesTsRepositoryName = RulesTsModuleName
+	RulesJestModuleName   = "aspect_rules_jest"
+	RulesGrpcJsModuleName = "rules_proto_grpc_js"
 	NpmRepositoryName     = "npm"
 )
 
 var sourceRuleKinds = treeset.NewWithStringComparator(TsProjectKind, JsLibraryKind, JsTestKind, TsProtoLibraryKind)
+var binaryRuleKinds = treeset.NewWithStringComparator(JsBinaryKind)
+var testRunnerRuleKinds = treeset.NewWithStringComparator(JestTestKind, VitestTestKind, MochaTestKind)
+var grpcWebRuleKinds = treeset.NewWithStringComparator(JsGrpcWebLibraryKind)
 
 // Kinds returns a map that maps rule names (kinds) and information on how to
 // match and merge attributes that may be found in rules of those kinds.
@@ -38,7 +47,8 @@ var tsKinds = map[string]rule.KindInfo{
 		},
 		SubstituteAttrs: map[string]bool{},
 		MergeableAttrs: map[string]bool{
-			"srcs": true,
+			"srcs":   true,
+			"assets": true,
 
 			// Generated based on project config.
 			"isolated_typecheck": true,
@@ -82,7 +92,9 @@ var tsKinds = map[string]rule.KindInfo{
 			"entry_point": true,
 		},
 		SubstituteAttrs: map[string]bool{},
-		MergeableAttrs:  map[string]bool{},
+		MergeableAttrs: map[string]bool{
+			"entry_point": true,
+		},
 		ResolveAttrs: map[string]bool{
 			"data": true,
 		},
@@ -112,6 +124,48 @@ var tsKinds = map[string]rule.KindInfo{
 			"deps": true,
 		},
 	},
+	JestTestKind: {
+		MatchAny: false,
+		NonEmptyAttrs: map[string]bool{
+			"config": true,
+		},
+		SubstituteAttrs: map[string]bool{},
+		MergeableAttrs: map[string]bool{
+			"config":       true,
+			"node_modules": true,
+		},
+		ResolveAttrs: map[string]bool{
+			"data": true,
+		},
+	},
+	VitestTestKind: {
+		MatchAny: false,
+		NonEmptyAttrs: map[string]bool{
+			"config": true,
+		},
+		SubstituteAttrs: map[string]bool{},
+		MergeableAttrs: map[string]bool{
+			"config":       true,
+			"node_modules": true,
+		},
+		ResolveAttrs: map[string]bool{
+			"data": true,
+		},
+	},
+	MochaTestKind: {
+		MatchAny: false,
+		NonEmptyAttrs: map[string]bool{
+			"config": true,
+		},
+		SubstituteAttrs: map[string]bool{},
+		MergeableAttrs: map[string]bool{
+			"config":       true,
+			"node_modules": true,
+		},
+		ResolveAttrs: map[string]bool{
+			"data": true,
+		},
+	},
 	TsConfigKind: {
 		NonEmptyAttrs: map[string]bool{
 			"src": true,
@@ -132,6 +186,18 @@ var tsKinds = map[string]rule.KindInfo{
 			"proto": true,
 		},
 	},
+	JsGrpcWebLibraryKind: {
+		MatchAny: false,
+		NonEmptyAttrs: map[string]bool{
+			"protos": true,
+		},
+		MergeableAttrs: map[string]bool{
+			"protos": true,
+		},
+		ResolveAttrs: map[string]bool{
+			"deps": true,
+		},
+	},
 	NpmLinkAllKind: {
 		MatchAny: true,
 	},
@@ -142,7 +208,9 @@ var tsKinds = map[string]rule.KindInfo{
 		},
 		SubstituteAttrs: map[string]bool{},
 		MergeableAttrs: map[string]bool{
-			"srcs": true,
+			"srcs":                  true,
+			"include_srcs_patterns": true,
+			"exclude_srcs_patterns": true,
 		},
 		ResolveAttrs: map[string]bool{
 			"srcs": true,
@@ -168,6 +236,18 @@ func (h *typeScriptLang) ApparentLoads(moduleToApparentName func(string) string)
 		jsModName = RulesJsRepositoryName
 	}
 
+	jestModName := moduleToApparentName(RulesJestModuleName)
+	if jestModName == "" {
+		jestModName = RulesJestModuleName
+	}
+
+	grpcJsModName := moduleToApparentName(RulesGrpcJsModuleName)
+	if grpcJsModName == "" {
+		grpcJsModName = RulesGrpcJsModuleName
+	}
+
+	// There are no standard vitest_test or mocha_test rules, they are expected to be
+	// mapped to custom macros using the gazelle map_kind directive.
 	return []rule.LoadInfo{
 		{
 			Name: "@" + tsModName + "//ts:defs.bzl",
@@ -184,6 +264,13 @@ func (h *typeScriptLang) ApparentLoads(moduleToApparentName func(string) string)
 			},
 		},
 
+		{
+			Name: "@" + grpcJsModName + "//:defs.bzl",
+			Symbols: []string{
+				JsGrpcWebLibraryKind,
+			},
+		},
+
 		{
 			Name: "@" + jsModName + "//npm:defs.bzl",
 			Symbols: []string{
@@ -205,6 +292,13 @@ func (h *typeScriptLang) ApparentLoads(moduleToApparentName func(string) string)
 			},
 		},
 
+		{
+			Name: "@" + jestModName + "//jest:defs.bzl",
+			Symbols: []string{
+				JestTestKind,
+			},
+		},
+
 		{
 			Name: "@" + NpmRepositoryName + "//:defs.bzl",
 			Symbols: []string{
@@ -1,6 +1,9 @@
 package gazelle
 
 import (
+	"context"
+
+	common "github.com/aspect-build/aspect-gazelle/common"
 	pnpm "github.com/aspect-build/aspect-gazelle/language/js/pnpm"
 	"github.com/aspect-build/aspect-gazelle/language/js/typescript"
 	"github.com/bazelbuild/bazel-gazelle/label"
@@ -15,13 +18,22 @@ var _ language.Language = (*typeScriptLang)(nil)
 // TypeScript satisfies the language.Language interface including the
 // Configurer and Resolver types.
 type typeScriptLang struct {
+	language.BaseLifecycleManager
+
 	// Importable files and the generating label.
 	fileLabels map[string]*label.Label
 
+	// The exports of source files by import path, recorded when narrowing barrel imports.
+	moduleExports map[string]*moduleExports
+
 	// Importable type definitions and the generating labels.
 	// Multiple labels may define/extend the same type definition, potentially also extending packages.
 	moduleTypes map[string][]*label.Label
 
+	// Matchers of wildcard type definitions such as `declare module "*.svg"`, the
+	// labels of each pattern are within moduleTypes.
+	moduleTypePatterns map[string]common.GlobExpr
+
 	// Importable npm-like packages. Each pnpm project has its own set
 	// of importable npm packages.
 	// BUILDs alongside pnpm project roots have a map. BUILDs within a project contain a reference
@@ -30,10 +42,20 @@ type typeScriptLang struct {
 
 	// TypeScript configuration across the workspace
 	tsconfig *typescript.TsWorkspace
+
+	// Directories containing a package.json, the scope of package.json subpath imports.
+	packageJsonDirs map[string]bool
+
+	// The npm dependency usage of pnpm projects by project directory.
+	npmDependencyUsage map[string]*npmDependencyUsage
+
+	// The targets and resolved dependencies of the import cycle validation by label.
+	importGraph map[string]*importGraphNode
 }
 
 var _ language.Language = (*typeScriptLang)(nil)
 var _ language.ModuleAwareLanguage = (*typeScriptLang)(nil)
+var _ language.LifecycleManager = (*typeScriptLang)(nil)
 
 // NewLanguage initializes a new TypeScript that satisfies the language.Language
 // interface. This is the entrypoint for the extension initialization.
@@ -41,9 +63,21 @@ func NewLanguage() language.Language {
 	pnpmProjects := pnpm.NewPnpmProjectMap()
 
 	return &typeScriptLang{
-		fileLabels:   make(map[string]*label.Label),
-		moduleTypes:  make(map[string][]*label.Label),
-		pnpmProjects: pnpmProjects,
-		tsconfig:     typescript.NewTsWorkspace(pnpmProjects),
+		fileLabels:    make(map[string]*label.Label),
+		moduleExports: make(map[string]*moduleExports),
+		moduleTypes:   make(map[string][]*label.Label),
+		pnpmProjects:  pnpmProjects,
+		tsconfig:      typescript.NewTsWorkspace(pnpmProjects),
+
+		moduleTypePatterns: make(map[string]common.GlobExpr),
+		packageJsonDirs:    make(map[string]bool),
+		npmDependencyUsage: make(map[string]*npmDependencyUsage),
+		importGraph:        make(map[string]*importGraphNode),
 	}
 }
+
+// Validations of the resolved dependencies of all targets.
+func (ts *typeScriptLang) AfterResolvingDeps(ctx context.Context) {
+	ts.reportUnusedNpmDependencies()
+	ts.reportImportCycles()
+}
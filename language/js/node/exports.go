@@ -0,0 +1,178 @@
+package gazelle
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+
+	BazelLog "github.com/aspect-build/aspect-gazelle/common/logger"
+	"github.com/msolo/jsonr"
+)
+
+// The package.json fields used to resolve imports of a package and its subpaths.
+type PackageEntryPoints struct {
+	Name  string
+	Main  string
+	Types string
+
+	// The "exports" subpath mappings, nil if the package has no "exports".
+	Exports []PackageTargetEntry
+
+	// The "typesVersions" subpath mappings of the first version range.
+	TypesVersions []PackageTargetEntry
+}
+
+func init() {
+	gob.Register(PackageEntryPoints{})
+}
+
+// Extract the package.json fields used to resolve imports of the package.
+func ParsePackageJsonEntryPoints(packageJsonReader io.Reader) (PackageEntryPoints, error) {
+	packageJsonDecoder := jsonr.NewDecoder(packageJsonReader)
+
+	var c npmPackageJSON
+	if err := packageJsonDecoder.Decode(&c); err != nil {
+		return PackageEntryPoints{}, err
+	}
+
+	exports, err := parsePackageExports(c.Exports)
+	if err != nil {
+		return PackageEntryPoints{}, err
+	}
+
+	typesVersions, err := parsePackageTypesVersions(c.TypesVersions)
+	if err != nil {
+		return PackageEntryPoints{}, err
+	}
+
+	types := c.Types
+	if types == "" {
+		types = c.Typings
+	}
+
+	return PackageEntryPoints{
+		Name:          c.Name,
+		Main:          c.Main,
+		Types:         types,
+		Exports:       exports,
+		TypesVersions: typesVersions,
+	}, nil
+}
+
+// Resolve a package subpath such as "." or "./feature/x" to the possible files
+// relative to the package root, in order of precedence.
+//
+// Follows the node package exports resolution with the given conditions, and
+// the TypeScript typesVersions resolution if the "types" condition is included.
+func (p PackageEntryPoints) Resolve(subpath string, conditions []string) []string {
+	possible := []string{}
+
+	// TypeScript typesVersions take precedence over exports and fields such as "types".
+	// See https://www.typescriptlang.org/docs/handbook/declaration-files/publishing.html#version-selection-with-typesversions
+	if slices.Contains(conditions, "types") && len(p.TypesVersions) > 0 {
+		typesSubpath := strings.TrimPrefix(subpath, "./")
+		if subpath == "." {
+			typesSubpath = path.Clean(p.Types)
+		}
+		if typesSubpath != "" && typesSubpath != "." {
+			possible = append(possible, expandSubpath(p.TypesVersions, typesSubpath, conditions)...)
+		}
+	}
+
+	if p.Exports != nil {
+		// Only the exported subpaths can be imported when "exports" is declared.
+		possible = append(possible, expandSubpath(p.Exports, subpath, conditions)...)
+	} else if subpath == "." {
+		if p.Types != "" && slices.Contains(conditions, "types") {
+			possible = append(possible, p.Types)
+		}
+		if p.Main != "" {
+			possible = append(possible, p.Main)
+		}
+		possible = append(possible, "index")
+	} else {
+		possible = append(possible, subpath)
+	}
+
+	for i, f := range possible {
+		possible[i] = path.Clean(f)
+	}
+
+	return possible
+}
+
+// Parse the package.json "exports" into subpath mappings, normalizing the
+// shorthand for only the "." subpath.
+//
+// See https://nodejs.org/docs/latest-v22.x/api/packages.html#exports-sugar
+func parsePackageExports(exportsJson json.RawMessage) ([]PackageTargetEntry, error) {
+	if len(exportsJson) == 0 {
+		return nil, nil
+	}
+
+	exports, err := parsePackageTarget(json.NewDecoder(bytes.NewReader(exportsJson)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid package.json exports: %w", err)
+	}
+
+	// A null "exports" is the same as no "exports"
+	if exports.isNull() {
+		return nil, nil
+	}
+
+	// Conditions or subpaths, which can not be mixed
+	if len(exports.Conditions) > 0 && strings.HasPrefix(exports.Conditions[0].Key, ".") {
+		for _, subpath := range exports.Conditions {
+			if !strings.HasPrefix(subpath.Key, ".") {
+				BazelLog.Warnf("Invalid package.json exports key %q, can not mix subpaths and conditions", subpath.Key)
+			}
+		}
+
+		return exports.Conditions, nil
+	}
+
+	return []PackageTargetEntry{{Key: ".", Target: exports}}, nil
+}
+
+// Parse the package.json "typesVersions" subpath mappings of the first version range.
+//
+// The TypeScript version is unknown so the first range is assumed to apply.
+func parsePackageTypesVersions(typesVersionsJson json.RawMessage) ([]PackageTargetEntry, error) {
+	if len(typesVersionsJson) == 0 {
+		return nil, nil
+	}
+
+	typesVersions, err := parsePackageTarget(json.NewDecoder(bytes.NewReader(typesVersionsJson)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid package.json typesVersions: %w", err)
+	}
+
+	if len(typesVersions.Conditions) == 0 {
+		return nil, nil
+	}
+
+	return typesVersions.Conditions[0].Target.Conditions, nil
+}
+
+func (t *PackageTarget) isNull() bool {
+	return t.Path == "" && len(t.Fallbacks) == 0 && len(t.Conditions) == 0
+}
+
+// Append all paths of a target and any nested fallbacks and conditions.
+func (t *PackageTarget) appendPaths(paths []string) []string {
+	if t.Path != "" {
+		paths = append(paths, path.Clean(t.Path))
+	}
+	for _, fallback := range t.Fallbacks {
+		paths = fallback.appendPaths(paths)
+	}
+	for _, condition := range t.Conditions {
+		paths = condition.Target.appendPaths(paths)
+	}
+	return paths
+}
@@ -0,0 +1,89 @@
+package gazelle
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPackageEntryPointsResolve(t *testing.T) {
+	t.Run("no exports", func(t *testing.T) {
+		packageJson := `{"name": "@myorg/lib", "main": "./dist/index.js", "types": "./dist/index.d.ts"}`
+
+		assertResolvePackageEntryPoints(t, packageJson, ".", []string{"import"}, "dist/index.js", "index")
+		assertResolvePackageEntryPoints(t, packageJson, ".", []string{"types"}, "dist/index.d.ts", "dist/index.js", "index")
+		assertResolvePackageEntryPoints(t, packageJson, "./feature/x", []string{"types"}, "feature/x")
+	})
+
+	t.Run("exports sugar", func(t *testing.T) {
+		assertResolvePackageEntryPoints(t, `{"exports": "./index.js"}`, ".", DefaultConditions, "index.js")
+		assertResolvePackageEntryPoints(t, `{"exports": "./index.js"}`, "./other", DefaultConditions)
+		assertResolvePackageEntryPoints(t, `{"exports": {"import": "./index.mjs", "require": "./index.cjs"}}`, ".", []string{"require"}, "index.cjs")
+		assertResolvePackageEntryPoints(t, `{"exports": null, "main": "main.js"}`, ".", DefaultConditions, "main.js", "index")
+	})
+
+	t.Run("nested conditions", func(t *testing.T) {
+		packageJson := `{
+			"exports": {
+				".": {
+					"browser": "./src/browser.js",
+					"import": {
+						"types": "./src/index.d.mts",
+						"default": "./src/index.mjs"
+					},
+					"default": "./src/index.cjs"
+				},
+				"./feature/*": {
+					"types": "./src/feature/*.d.ts",
+					"default": ["./src/feature/*.js", "./src/feature/*/index.js"]
+				},
+				"./feature/internal/*": null,
+				"./package.json": "./package.json"
+			}
+		}`
+
+		assertResolvePackageEntryPoints(t, packageJson, ".", []string{"import"}, "src/index.mjs", "src/index.cjs")
+		assertResolvePackageEntryPoints(t, packageJson, ".", []string{"import", "types"}, "src/index.d.mts", "src/index.mjs", "src/index.cjs")
+		assertResolvePackageEntryPoints(t, packageJson, ".", []string{"browser"}, "src/browser.js", "src/index.cjs")
+		assertResolvePackageEntryPoints(t, packageJson, "./feature/x", []string{"import"}, "src/feature/x.js", "src/feature/x/index.js")
+		assertResolvePackageEntryPoints(t, packageJson, "./feature/internal/x", []string{"import"})
+		assertResolvePackageEntryPoints(t, packageJson, "./package.json", nil, "package.json")
+		assertResolvePackageEntryPoints(t, packageJson, "./other", DefaultConditions)
+	})
+
+	t.Run("typesVersions", func(t *testing.T) {
+		packageJson := `{
+			"types": "./index.d.ts",
+			"typesVersions": {
+				">=4.2": {
+					"index.d.ts": ["./types/index.d.ts"],
+					"feature/*": ["./types/feature/*.d.ts"]
+				},
+				"*": {
+					"*": ["./ts-old/*"]
+				}
+			}
+		}`
+
+		assertResolvePackageEntryPoints(t, packageJson, ".", []string{"types"}, "types/index.d.ts", "index.d.ts", "index")
+		assertResolvePackageEntryPoints(t, packageJson, "./feature/x", []string{"types"}, "types/feature/x.d.ts", "feature/x")
+		assertResolvePackageEntryPoints(t, packageJson, "./feature/x", []string{"import"}, "feature/x")
+	})
+}
+
+func assertResolvePackageEntryPoints(t *testing.T, packageJson, subpath string, conditions []string, expected ...string) {
+	t.Helper()
+
+	entryPoints, err := ParsePackageJsonEntryPoints(strings.NewReader(packageJson))
+	if err != nil {
+		t.Fatalf("ParsePackageJsonEntryPoints failed: %v:\n\t%s", err, packageJson)
+	}
+
+	actual := entryPoints.Resolve(subpath, conditions)
+	if len(actual) == 0 && len(expected) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Resolve(%q, %v): expected %v, got %v", subpath, conditions, expected, actual)
+	}
+}
@@ -0,0 +1,199 @@
+package gazelle
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	BazelLog "github.com/aspect-build/aspect-gazelle/common/logger"
+	"github.com/msolo/jsonr"
+)
+
+// The conditions matched when expanding package.json conditional targets.
+// The "default" condition is always matched.
+//
+// See https://nodejs.org/docs/latest-v22.x/api/packages.html#conditional-exports
+var DefaultConditions = []string{"types", "import", "require", "node"}
+
+// A package.json "imports" or "exports" target.
+//
+// A target is either a path, a list of fallback targets, a set of conditional
+// targets in declaration order, or null when none are set.
+type PackageTarget struct {
+	Path       string
+	Fallbacks  []*PackageTarget
+	Conditions []PackageTargetEntry
+}
+
+// A keyed PackageTarget such as a condition or subpath.
+type PackageTargetEntry struct {
+	Key    string
+	Target *PackageTarget
+}
+
+// The package.json "imports" subpath mappings in declaration order.
+//
+// See https://nodejs.org/docs/latest-v22.x/api/packages.html#subpath-imports
+type PackageImports struct {
+	Subpaths []PackageTargetEntry
+}
+
+func init() {
+	gob.Register(PackageImports{})
+}
+
+type npmPackageImportsJSON struct {
+	// imports: https://nodejs.org/docs/latest-v22.x/api/packages.html#imports
+	Imports json.RawMessage `json:"imports"`
+}
+
+// Extract the package.json 'imports' subpath mappings.
+func ParsePackageJsonSubpathImports(packageJsonReader io.Reader) (PackageImports, error) {
+	packageJsonDecoder := jsonr.NewDecoder(packageJsonReader)
+
+	var c npmPackageImportsJSON
+	if err := packageJsonDecoder.Decode(&c); err != nil {
+		return PackageImports{}, err
+	}
+
+	if len(c.Imports) == 0 {
+		return PackageImports{}, nil
+	}
+
+	// Decode the raw json to maintain the order of the subpaths and conditions
+	imports, err := parsePackageTarget(json.NewDecoder(bytes.NewReader(c.Imports)))
+	if err != nil {
+		return PackageImports{}, fmt.Errorf("invalid package.json imports: %w", err)
+	}
+
+	if imports.Path != "" || len(imports.Fallbacks) > 0 {
+		BazelLog.Warnf("Unknown package.json imports type, expected object")
+		return PackageImports{}, nil
+	}
+
+	for _, subpath := range imports.Conditions {
+		if !strings.HasPrefix(subpath.Key, "#") {
+			BazelLog.Warnf("Invalid package.json imports key %q, must start with '#'", subpath.Key)
+		}
+	}
+
+	return PackageImports{Subpaths: imports.Conditions}, nil
+}
+
+func parsePackageTarget(d *json.Decoder) (*PackageTarget, error) {
+	t, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	target := &PackageTarget{}
+
+	switch v := t.(type) {
+	case nil:
+		// Null targets exclude subpaths
+		break
+	case string:
+		target.Path = v
+	case json.Delim:
+		switch v {
+		case '[':
+			for d.More() {
+				fallback, err := parsePackageTarget(d)
+				if err != nil {
+					return nil, err
+				}
+				target.Fallbacks = append(target.Fallbacks, fallback)
+			}
+		case '{':
+			for d.More() {
+				key, err := d.Token()
+				if err != nil {
+					return nil, err
+				}
+
+				entryTarget, err := parsePackageTarget(d)
+				if err != nil {
+					return nil, err
+				}
+				target.Conditions = append(target.Conditions, PackageTargetEntry{Key: key.(string), Target: entryTarget})
+			}
+		}
+
+		// The closing ']' or '}'
+		if _, err := d.Token(); err != nil {
+			return nil, err
+		}
+	default:
+		BazelLog.Warnf("Unknown package.json target type: %T", v)
+	}
+
+	return target, nil
+}
+
+// Expand a subpath import such as "#utils/a" to the possible targets.
+//
+// Local targets are relative to the package.json such as "./src/utils/a.js", other
+// targets are package specifiers.
+func (pi PackageImports) Expand(specifier string, conditions []string) []string {
+	return expandSubpath(pi.Subpaths, specifier, conditions)
+}
+
+// Expand a subpath using the node PACKAGE_IMPORTS_EXPORTS_RESOLVE algorithm.
+//
+// Unlike node all targets matching the conditions are returned instead of only the first.
+func expandSubpath(subpaths []PackageTargetEntry, subpath string, conditions []string) []string {
+	// Exact matches take precedence over patterns
+	for _, entry := range subpaths {
+		if entry.Key == subpath && !strings.Contains(entry.Key, "*") {
+			return entry.Target.expand("", conditions)
+		}
+	}
+
+	// The best pattern match, see PATTERN_KEY_COMPARE
+	var best *PackageTargetEntry
+	bestStar := -1
+	for i, entry := range subpaths {
+		star := strings.IndexByte(entry.Key, '*')
+		if star == -1 || star != strings.LastIndexByte(entry.Key, '*') {
+			continue
+		}
+
+		prefix, suffix := entry.Key[:star], entry.Key[star+1:]
+		if len(subpath) < len(entry.Key) || !strings.HasPrefix(subpath, prefix) || !strings.HasSuffix(subpath, suffix) {
+			continue
+		}
+
+		if best == nil || star > bestStar || (star == bestStar && len(entry.Key) > len(best.Key)) {
+			best = &subpaths[i]
+			bestStar = star
+		}
+	}
+
+	if best == nil {
+		return nil
+	}
+
+	match := subpath[bestStar : len(subpath)-(len(best.Key)-bestStar-1)]
+	return best.Target.expand(match, conditions)
+}
+
+func (t *PackageTarget) expand(match string, conditions []string) []string {
+	if t.Path != "" {
+		return []string{strings.ReplaceAll(t.Path, "*", match)}
+	}
+
+	targets := []string{}
+	for _, fallback := range t.Fallbacks {
+		targets = append(targets, fallback.expand(match, conditions)...)
+	}
+	for _, condition := range t.Conditions {
+		if condition.Key == "default" || slices.Contains(conditions, condition.Key) {
+			targets = append(targets, condition.Target.expand(match, conditions)...)
+		}
+	}
+	return targets
+}
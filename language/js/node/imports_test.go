@@ -0,0 +1,88 @@
+package gazelle
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePackageJsonSubpathImports(t *testing.T) {
+	t.Run("no imports", func(t *testing.T) {
+		assertExpandPackageImports(t, `{"name": "foo"}`, "#foo")
+	})
+
+	t.Run("exact subpaths", func(t *testing.T) {
+		packageJson := `{
+			"imports": {
+				"#a": "./src/a.js",
+				"#b": "lodash",
+				"#c": null,
+				"#d": ["./src/d.js", "./src/d-fallback.js"]
+			}
+		}`
+
+		assertExpandPackageImports(t, packageJson, "#a", "./src/a.js")
+		assertExpandPackageImports(t, packageJson, "#b", "lodash")
+		assertExpandPackageImports(t, packageJson, "#c")
+		assertExpandPackageImports(t, packageJson, "#d", "./src/d.js", "./src/d-fallback.js")
+		assertExpandPackageImports(t, packageJson, "#e")
+		assertExpandPackageImports(t, packageJson, "#a/b")
+	})
+
+	t.Run("subpath patterns", func(t *testing.T) {
+		packageJson := `{
+			"imports": {
+				"#utils/*": "./src/utils/*.js",
+				"#utils/internal/*": null,
+				"#utils/x/*.js": "./src/x/*.mjs",
+				"#deep/*": "./src/*/*/index.js",
+			}
+		}`
+
+		assertExpandPackageImports(t, packageJson, "#utils/a", "./src/utils/a.js")
+		assertExpandPackageImports(t, packageJson, "#utils/a/b", "./src/utils/a/b.js")
+		assertExpandPackageImports(t, packageJson, "#utils/internal/a")
+		assertExpandPackageImports(t, packageJson, "#utils/x/y.js", "./src/x/y.mjs")
+		assertExpandPackageImports(t, packageJson, "#deep/a", "./src/a/a/index.js")
+		assertExpandPackageImports(t, packageJson, "#utils/")
+	})
+
+	t.Run("conditions", func(t *testing.T) {
+		packageJson := `{
+			"imports": {
+				"#dep": {
+					"node": "dep-node-native",
+					"browser": "./src/dep-browser.js",
+					"default": "./src/dep-polyfill.js"
+				},
+				"#nested/*": {
+					"import": {
+						"types": "./types/*.d.ts",
+						"default": "./esm/*.mjs"
+					},
+					"require": "./cjs/*.cjs"
+				}
+			}
+		}`
+
+		assertExpandPackageImports(t, packageJson, "#dep", "dep-node-native", "./src/dep-polyfill.js")
+		assertExpandPackageImports(t, packageJson, "#nested/a", "./types/a.d.ts", "./esm/a.mjs", "./cjs/a.cjs")
+	})
+}
+
+func assertExpandPackageImports(t *testing.T, packageJson, specifier string, expected ...string) {
+	t.Helper()
+
+	imports, err := ParsePackageJsonSubpathImports(strings.NewReader(packageJson))
+	if err != nil {
+		t.Fatalf("failed to parse package.json imports: %v", err)
+	}
+
+	actual := imports.Expand(specifier, DefaultConditions)
+	if len(actual) == 0 && len(expected) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expand(%q): expected %v, got %v", specifier, expected, actual)
+	}
+}
@@ -1,27 +1,86 @@
 package gazelle
 
 import (
+	"encoding/gob"
+	"encoding/json"
 	"io"
 	"path"
+	"slices"
+	"strings"
 
 	BazelLog "github.com/aspect-build/aspect-gazelle/common/logger"
 	"github.com/msolo/jsonr"
 )
 
 type npmPackageJSON struct {
+	// name: https://docs.npmjs.com/cli/v10/configuring-npm/package-json#name
+	Name string `json:"name"`
+
+	// bin: https://docs.npmjs.com/cli/v10/configuring-npm/package-json#bin
+	Bin interface{} `json:"bin"`
+
 	// main: https://nodejs.org/docs/latest-v22.x/api/packages.html#main
 	Main string `json:"main"`
 
 	// exports: https://nodejs.org/docs/latest-v22.x/api/packages.html#exports
-	Exports interface{} `json:"exports"`
+	Exports json.RawMessage `json:"exports"`
 
 	// types/typings: https://www.typescriptlang.org/docs/handbook/declaration-files/publishing.html#including-declarations-in-your-npm-package
 	Types   string `json:"types"`
 	Typings string `json:"typings"`
+
+	// typesVersions: https://www.typescriptlang.org/docs/handbook/declaration-files/publishing.html#version-selection-with-typesversions
+	TypesVersions json.RawMessage `json:"typesVersions"`
+
+	// files: https://docs.npmjs.com/cli/v10/configuring-npm/package-json#files
+	Files []string `json:"files"`
+
+	// publishConfig: https://docs.npmjs.com/cli/v10/configuring-npm/package-json#publishconfig
+	// including the entry points overridden when publishing: https://pnpm.io/package_json#publishconfig
+	PublishConfig *npmPackageJSON `json:"publishConfig"`
+}
+
+// The package.json with the publishConfig overrides of the entry points applied.
+func (c npmPackageJSON) published() npmPackageJSON {
+	p := c.PublishConfig
+	if p == nil {
+		return c
+	}
+
+	if p.Main != "" {
+		c.Main = p.Main
+	}
+	if p.Types != "" || p.Typings != "" {
+		c.Types, c.Typings = p.Types, p.Typings
+	}
+	if p.Exports != nil {
+		c.Exports = p.Exports
+	}
+	if p.TypesVersions != nil {
+		c.TypesVersions = p.TypesVersions
+	}
+	if p.Bin != nil {
+		c.Bin = p.Bin
+	}
+
+	return c
+}
+
+// The package.json fields determining the files published by `npm pack`.
+type PackagePublishFiles struct {
+	// The "files" patterns, nil if not specified.
+	Files []string
+
+	// The "main" and "bin" files, always published.
+	EntryFiles []string
+}
+
+func init() {
+	gob.Register(PackagePublishFiles{})
 }
 
 // Extract the various import types from the package.json file such as
-// 'main' and 'exports' fields.
+// 'main' and 'exports' fields, with the 'publishConfig' overrides applied.
 func ParsePackageJsonImports(packageJsonReader io.Reader) ([]string, error) {
 	packageJsonDecoder := jsonr.NewDecoder(packageJsonReader)
 
@@ -29,6 +88,7 @@ func ParsePackageJsonImports(packageJsonReader io.Reader) ([]string, error) {
 	if err := packageJsonDecoder.Decode(&c); err != nil {
 		return nil, err
 	}
+	c = c.published()
 
 	imports := []string{}
 
@@ -43,56 +103,94 @@ func ParsePackageJsonImports(packageJsonReader io.Reader) ([]string, error) {
 	}
 
 	// https://nodejs.org/api/packages.html#exports
-	if c.Exports != nil {
-		switch exports := c.Exports.(type) {
-		case string:
-			// Single export
-			imports = append(imports, path.Clean(exports))
-		case map[string]interface{}:
-			// Subpath exports
-			for exportKey, export := range exports {
-				switch e := export.(type) {
-				case string:
-					// Regular subpath export
-					imports = append(imports, path.Clean(e))
-				case nil:
-					// According to https://nodejs.org/api/packages.html#subpath-patterns, to exclude
-					// private subfolders from patterns, null targets can be used:
-					// {
-					//   "exports": {
-					// 	   "./features/*.js": "./src/features/*.js",
-					// 	   "./features/private-internal/*": null
-					// 	 }
-					// }
-					break
-				case map[string]interface{}:
-					// Conditional subpath export
-					for subEKey, subE := range e {
-						switch subE := subE.(type) {
-						case string:
-							imports = append(imports, path.Clean(subE))
-						default:
-							BazelLog.Warnf("Unknown package.json exports.%s.%s type: %T", exportKey, subEKey, subE)
-						}
-					}
-				default:
-					BazelLog.Warnf("Unknown package.json exports.%s type: %T", exportKey, export)
-				}
-			}
-		case []interface{}:
-			// Array of subpath exports
-			for i, subE := range exports {
-				switch subE := subE.(type) {
-				case string:
-					imports = append(imports, path.Clean(subE))
-				default:
-					BazelLog.Warnf("Unknown package.json exports[%v] type: %T", i, subE)
-				}
+	// All targets of all conditions, including nested conditions and fallbacks.
+	exports, err := parsePackageExports(c.Exports)
+	if err != nil {
+		return nil, err
+	}
+	for _, subpath := range exports {
+		imports = subpath.Target.appendPaths(imports)
+	}
+
+	typesVersions, err := parsePackageTypesVersions(c.TypesVersions)
+	if err != nil {
+		return nil, err
+	}
+	for _, subpath := range typesVersions {
+		imports = subpath.Target.appendPaths(imports)
+	}
+
+	return imports, nil
+}
+
+// Extract the package.json fields determining the files published by `npm pack`,
+// with the 'publishConfig' overrides applied.
+func ParsePackageJsonPublishFiles(packageJsonReader io.Reader) (PackagePublishFiles, error) {
+	packageJsonDecoder := jsonr.NewDecoder(packageJsonReader)
+
+	var c npmPackageJSON
+	if err := packageJsonDecoder.Decode(&c); err != nil {
+		return PackagePublishFiles{}, err
+	}
+	c = c.published()
+
+	entryFiles := []string{}
+	if c.Main != "" {
+		entryFiles = append(entryFiles, path.Clean(c.Main))
+	}
+	for _, bin := range parseBin(c) {
+		entryFiles = append(entryFiles, bin)
+	}
+	slices.Sort(entryFiles)
+
+	return PackagePublishFiles{
+		Files:      c.Files,
+		EntryFiles: slices.Compact(entryFiles),
+	}, nil
+}
+
+// Extract the package.json 'bin' entries as a map of command name to file.
+//
+// A single string 'bin' is named after the package, excluding any @scope.
+func ParsePackageJsonBin(packageJsonReader io.Reader) (map[string]string, error) {
+	packageJsonDecoder := jsonr.NewDecoder(packageJsonReader)
+
+	var c npmPackageJSON
+	if err := packageJsonDecoder.Decode(&c); err != nil {
+		return nil, err
+	}
+
+	return parseBin(c), nil
+}
+
+func parseBin(c npmPackageJSON) map[string]string {
+	bins := map[string]string{}
+
+	switch bin := c.Bin.(type) {
+	case nil:
+		break
+	case string:
+		name := c.Name
+		if i := strings.LastIndexByte(name, '/'); i != -1 {
+			name = name[i+1:]
+		}
+		if name == "" {
+			BazelLog.Warnf("Unable to determine package.json bin name without a package name")
+			break
+		}
+		bins[name] = path.Clean(bin)
+	case map[string]interface{}:
+		for name, b := range bin {
+			switch b := b.(type) {
+			case string:
+				bins[name] = path.Clean(b)
+			default:
+				BazelLog.Warnf("Unknown package.json bin.%s type: %T", name, b)
 			}
-		default:
-			BazelLog.Warnf("Unknown package.json exports type: %T", exports)
 		}
+	default:
+		BazelLog.Warnf("Unknown package.json bin type: %T", bin)
 	}
 
-	return imports, nil
+	return bins
 }
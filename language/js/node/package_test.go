@@ -1,6 +1,7 @@
 package gazelle
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -29,10 +30,93 @@ func TestParsePackageJsonImports(t *testing.T) {
 		assertParsePackageJsonImports(t, `{"exports":["./foo.js"]}`, "foo.js")
 	})
 
+	t.Run("nested exports", func(t *testing.T) {
+		// Nested conditions
+		assertParsePackageJsonImports(t, `{"exports":{".":{"import":{"types":"./index.d.mts","default":"./index.mjs"},"require":"./index.cjs"}}}`, "index.d.mts", "index.mjs", "index.cjs")
+
+		// Arrays within conditions
+		assertParsePackageJsonImports(t, `{"exports":{"node":["./a.js","./b.js"],"default":"./c.js"}}`, "a.js", "b.js", "c.js")
+
+		// Subpath patterns
+		assertParsePackageJsonImports(t, `{"exports":{"./features/*.js":"./src/features/*.js","./features/private/*":null}}`, "src/features/*.js")
+	})
+
+	t.Run("typesVersions", func(t *testing.T) {
+		assertParsePackageJsonImports(t, `{"types":"index.d.ts","typesVersions":{"<4.0":{"*":["ts3/*"]},"*":{"index.d.ts":["ts4/index.d.ts"]}}}`, "index.d.ts", "ts3/*")
+	})
+
 	t.Run("invalid exports", func(t *testing.T) {
 		assertParsePackageJsonImports(t, `{"exports":null}`)
 		assertParsePackageJsonImports(t, `{"exports":{"./subpath":123, "x": []}}`)
 	})
+
+	t.Run("publishConfig", func(t *testing.T) {
+		assertParsePackageJsonImports(t, `{"main":"src/index.ts","publishConfig":{"main":"dist/index.js"}}`, "dist/index.js")
+		assertParsePackageJsonImports(t, `{"main":"index.js","types":"index.d.ts","publishConfig":{"typings":"dist/index.d.ts"}}`, "index.js", "dist/index.d.ts")
+		assertParsePackageJsonImports(t, `{"exports":"./src/index.ts","publishConfig":{"access":"public"}}`, "src/index.ts")
+	})
+}
+
+func TestParsePackageJsonPublishFiles(t *testing.T) {
+	t.Run("no files", func(t *testing.T) {
+		assertParsePackageJsonPublishFiles(t, `{"name":"foo"}`, PackagePublishFiles{EntryFiles: []string{}})
+	})
+
+	t.Run("files", func(t *testing.T) {
+		assertParsePackageJsonPublishFiles(t, `{"files":["dist","!dist/*.map"]}`, PackagePublishFiles{Files: []string{"dist", "!dist/*.map"}, EntryFiles: []string{}})
+		assertParsePackageJsonPublishFiles(t, `{"files":[]}`, PackagePublishFiles{Files: []string{}, EntryFiles: []string{}})
+	})
+
+	t.Run("entry files", func(t *testing.T) {
+		assertParsePackageJsonPublishFiles(t, `{"name":"foo","main":"./index.js","bin":"./cli.js"}`, PackagePublishFiles{EntryFiles: []string{"cli.js", "index.js"}})
+		assertParsePackageJsonPublishFiles(t, `{"main":"index.js","bin":{"a":"./index.js"}}`, PackagePublishFiles{EntryFiles: []string{"index.js"}})
+		assertParsePackageJsonPublishFiles(t, `{"main":"src/index.ts","publishConfig":{"main":"dist/index.js"}}`, PackagePublishFiles{EntryFiles: []string{"dist/index.js"}})
+	})
+}
+
+func assertParsePackageJsonPublishFiles(t *testing.T, packageJson string, expected PackagePublishFiles) {
+	files, err := ParsePackageJsonPublishFiles(strings.NewReader(packageJson))
+
+	if err != nil {
+		t.Errorf("ParsePackageJsonPublishFiles failed: %v:\n\t%s", err, packageJson)
+		return
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("ParsePackageJsonPublishFiles(%q) expected %v, got %v", packageJson, expected, files)
+	}
+}
+
+func TestParsePackageJsonBin(t *testing.T) {
+	t.Run("no bin", func(t *testing.T) {
+		assertParsePackageJsonBin(t, `{"name":"foo"}`, map[string]string{})
+	})
+
+	t.Run("string bin", func(t *testing.T) {
+		assertParsePackageJsonBin(t, `{"name":"foo","bin":"./cli.js"}`, map[string]string{"foo": "cli.js"})
+		assertParsePackageJsonBin(t, `{"name":"@scope/foo","bin":"bin/../cli.js"}`, map[string]string{"foo": "cli.js"})
+		assertParsePackageJsonBin(t, `{"bin":"./cli.js"}`, map[string]string{})
+	})
+
+	t.Run("object bin", func(t *testing.T) {
+		assertParsePackageJsonBin(t, `{"bin":{"a":"./a.js","b":"bin/b.js"}}`, map[string]string{"a": "a.js", "b": "bin/b.js"})
+		assertParsePackageJsonBin(t, `{"bin":{"a":"./a.js","b":123}}`, map[string]string{"a": "a.js"})
+	})
+
+	t.Run("invalid bin", func(t *testing.T) {
+		assertParsePackageJsonBin(t, `{"name":"foo","bin":["./a.js"]}`, map[string]string{})
+	})
+}
+
+func assertParsePackageJsonBin(t *testing.T, packageJson string, expected map[string]string) {
+	bins, err := ParsePackageJsonBin(strings.NewReader(packageJson))
+
+	if err != nil {
+		t.Errorf("ParsePackageJsonBin failed: %v:\n\t%s", err, packageJson)
+		return
+	}
+	if !reflect.DeepEqual(bins, expected) {
+		t.Errorf("ParsePackageJsonBin(%q) expected %v, got %v", packageJson, expected, bins)
+	}
 }
 
 func assertParsePackageJsonImports(t *testing.T, packageJson string, expectedImports ...string) {
@@ -2,6 +2,7 @@ package gazelle
 
 import (
 	_ "embed"
+	"strconv"
 	"strings"
 
 	"github.com/emirpasic/gods/sets/treeset"
@@ -22,6 +23,107 @@ func createNativeModulesSet() *treeset.Set {
 	return set
 }
 
+// A node version such as 20.11, the zero value representing the latest version.
+type NodeVersion struct {
+	Major, Minor int
+}
+
+// The node versions adding builtin modules, builtin modules not listed have
+// been available since node 8.
+var nativeModuleVersions = map[string]NodeVersion{
+	"assert/strict":       {15, 0},
+	"async_hooks":         {8, 1},
+	"diagnostics_channel": {15, 1},
+	"dns/promises":        {15, 0},
+	"fs/promises":         {14, 0},
+	"http2":               {8, 4},
+	"inspector/promises":  {19, 0},
+	"path/posix":          {15, 3},
+	"path/win32":          {15, 3},
+	"perf_hooks":          {8, 5},
+	"readline/promises":   {17, 0},
+	"stream/consumers":    {16, 7},
+	"stream/promises":     {15, 0},
+	"stream/web":          {16, 5},
+	"timers/promises":     {15, 0},
+	"trace_events":        {10, 0},
+	"util/types":          {15, 3},
+	"wasi":                {13, 3},
+	"worker_threads":      {11, 7},
+}
+
+// Builtin modules that can only be imported with the "node:" prefix, and the
+// node versions adding them.
+var nodePrefixOnlyModules = map[string]NodeVersion{
+	"node:sea":            {20, 12},
+	"node:sqlite":         {22, 5},
+	"node:test":           {18, 0},
+	"node:test/reporters": {19, 9},
+}
+
+// Parse a node version such as "20", "22.5" or "v18.17.0".
+func ParseNodeVersion(version string) (NodeVersion, bool) {
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(version), "v"), ".")
+	if len(parts) > 3 {
+		return NodeVersion{}, false
+	}
+
+	major, err := strconv.Atoi(parts[0])
+	if err != nil || major <= 0 {
+		return NodeVersion{}, false
+	}
+
+	minor := 0
+	if len(parts) > 1 {
+		if minor, err = strconv.Atoi(parts[1]); err != nil || minor < 0 {
+			return NodeVersion{}, false
+		}
+	}
+
+	return NodeVersion{Major: major, Minor: minor}, true
+}
+
+func (v NodeVersion) IsLatest() bool {
+	return v == NodeVersion{}
+}
+
+func (v NodeVersion) String() string {
+	return strconv.Itoa(v.Major) + "." + strconv.Itoa(v.Minor)
+}
+
+// If the version is the same or newer than the other version.
+func (v NodeVersion) AtLeast(other NodeVersion) bool {
+	return v.IsLatest() || v.Major > other.Major || (v.Major == other.Major && v.Minor >= other.Minor)
+}
+
 func IsNodeImport(imprt string) bool {
 	return strings.HasPrefix(imprt, "node:") || nativeModulesSet.Contains(imprt)
 }
+
+// If the import is a builtin module of the node version, including modules such
+// as "node:test" that can only be imported with the "node:" prefix.
+func IsNodeBuiltinImport(imprt string, version NodeVersion) bool {
+	if version.IsLatest() {
+		return IsNodeImport(imprt)
+	}
+
+	if added, isPrefixOnly := nodePrefixOnlyModules[imprt]; isPrefixOnly {
+		return version.AtLeast(added)
+	}
+
+	module := strings.TrimPrefix(imprt, "node:")
+	if !nativeModulesSet.Contains(module) {
+		return false
+	}
+
+	// The "node:" prefix was added in node 14.18 and 16.0
+	if module != imprt && !version.AtLeast(NodeVersion{14, 18}) {
+		return false
+	}
+
+	if added, isVersioned := nativeModuleVersions[module]; isVersioned {
+		return version.AtLeast(added)
+	}
+
+	return true
+}
@@ -0,0 +1,66 @@
+package gazelle
+
+import "testing"
+
+func TestParseNodeVersion(t *testing.T) {
+	for version, expected := range map[string]NodeVersion{
+		"20":       {20, 0},
+		"22.5":     {22, 5},
+		"v18.17.0": {18, 17},
+		" 16.4 ":   {16, 4},
+	} {
+		actual, ok := ParseNodeVersion(version)
+		if !ok || actual != expected {
+			t.Errorf("ParseNodeVersion(%q): actual %v (%v), expected %v", version, actual, ok, expected)
+		}
+	}
+
+	for _, version := range []string{"", "latest", "v", "0", "-1", "20.x", "1.2.3.4"} {
+		if actual, ok := ParseNodeVersion(version); ok {
+			t.Errorf("ParseNodeVersion(%q): expected to be invalid, actual %v", version, actual)
+		}
+	}
+}
+
+func TestIsNodeBuiltinImport(t *testing.T) {
+	for _, tc := range []struct {
+		imprt    string
+		version  NodeVersion
+		expected bool
+	}{
+		// Latest, any "node:" import
+		{"fs", NodeVersion{}, true},
+		{"node:fs", NodeVersion{}, true},
+		{"node:test", NodeVersion{}, true},
+		{"node:unknown", NodeVersion{}, true},
+		{"lodash", NodeVersion{}, false},
+
+		// Modules available in all versions
+		{"fs", NodeVersion{8, 0}, true},
+		{"node:fs", NodeVersion{20, 0}, true},
+		{"node:unknown", NodeVersion{20, 0}, false},
+		{"lodash", NodeVersion{20, 0}, false},
+
+		// The "node:" prefix
+		{"node:fs", NodeVersion{14, 0}, false},
+		{"node:fs", NodeVersion{14, 18}, true},
+
+		// Modules added in later versions
+		{"fs/promises", NodeVersion{12, 0}, false},
+		{"fs/promises", NodeVersion{14, 0}, true},
+		{"stream/web", NodeVersion{16, 4}, false},
+		{"node:stream/web", NodeVersion{16, 5}, true},
+
+		// Modules only available with the "node:" prefix
+		{"test", NodeVersion{22, 0}, false},
+		{"node:test", NodeVersion{16, 0}, false},
+		{"node:test", NodeVersion{18, 0}, true},
+		{"node:sqlite", NodeVersion{22, 4}, false},
+		{"node:sqlite", NodeVersion{22, 5}, true},
+		{"node:sqlite", NodeVersion{23, 0}, true},
+	} {
+		if actual := IsNodeBuiltinImport(tc.imprt, tc.version); actual != tc.expected {
+			t.Errorf("IsNodeBuiltinImport(%q, %v): actual %v, expected %v", tc.imprt, tc.version, actual, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package gazelle
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	BazelLog "github.com/aspect-build/aspect-gazelle/common/logger"
+	node "github.com/aspect-build/aspect-gazelle/language/js/node"
+	"github.com/bazelbuild/bazel-gazelle/config"
+	"github.com/bazelbuild/bazel-gazelle/label"
+)
+
+// Validation of the npm dependencies declared by pnpm projects:
+//   - phantom dependencies: packages imported by a project only declared by a parent project
+//   - unused dependencies: packages declared by a project not imported by any source of the project
+
+// The npm dependency usage of a pnpm project.
+type npmDependencyUsage struct {
+	// The config of the pnpm project directory
+	c *config.Config
+
+	// The declared packages imported by sources of the project
+	used map[string]bool
+}
+
+// Enable the validation of the npm dependencies of the pnpm project in the directory.
+func (ts *typeScriptLang) addNpmDependencyValidation(c *config.Config, project string) {
+	cfg := c.Exts[LanguageName].(*JsGazelleConfig)
+	if cfg.ValidateNpmDependencies() == ValidationOff {
+		return
+	}
+
+	ts.npmDependencyUsage[project] = &npmDependencyUsage{
+		c:    c,
+		used: make(map[string]bool),
+	}
+}
+
+// Record the npm packages used by an import of projects validating their npm
+// dependencies, returning an error if a package is a phantom dependency resolved
+// through a parent project.
+func (ts *typeScriptLang) validateNpmDependency(cfg *JsGazelleConfig, from label.Label, imp ImportStatement, resolutionType ResolutionType, dep *label.Label, types []*label.Label) error {
+	validatePhantom := cfg.ValidateNpmDependencies() != ValidationOff
+	if !validatePhantom && len(ts.npmDependencyUsage) == 0 {
+		return nil
+	}
+
+	fromProject := ts.pnpmProjects.GetProject(from.Pkg)
+	if fromProject == nil {
+		return nil
+	}
+
+	// The packages that may be used by the import
+	var pkgs []string
+	if resolutionType == Resolution_NativeNode {
+		pkgs = []string{"@types/node"}
+	} else if pkg, _ := node.ParseImportPath(imp.Imp); pkg != "" {
+		pkgs = []string{pkg, node.ToAtTypesPackage(pkg)}
+	}
+
+	for _, pkg := range pkgs {
+		declaringProject := fromProject.GetDeclaringProject(pkg)
+		if declaringProject == nil {
+			continue
+		}
+
+		if usage := ts.npmDependencyUsage[declaringProject.Pkg()]; usage != nil {
+			usage.used[pkg] = true
+		}
+
+		if declaringProject == fromProject || !validatePhantom {
+			continue
+		}
+
+		// Phantom dependencies resolved to the package of the parent project
+		pkgLabel := declaringProject.Get(pkg)
+		isPkgLabel := func(l *label.Label) bool { return l != nil && l.Equal(*pkgLabel) }
+		if !isPkgLabel(dep) && !slices.ContainsFunc(types, isPkgLabel) {
+			continue
+		}
+
+		BazelLog.Debugf("import %q for target %v is a phantom dependency of %q", imp.ImportPath, from, declaringProject.Pkg())
+
+		return fmt.Errorf(
+			"Import %[1]q from %[2]q is a phantom dependency on the package %[3]q declared by %[4]q but not %[5]q. Possible solutions:\n"+
+				"\t1. Add %[3]q to the dependencies of %[5]q.\n"+
+				"\t2. Disable Gazelle npm dependency validation using '# aspect:%[6]s off'",
+			imp.ImportPath, imp.SourcePath, pkg,
+			path.Join(declaringProject.Pkg(), NpmPackageFilename), path.Join(fromProject.Pkg(), NpmPackageFilename),
+			Directive_ValidateNpmDependencies,
+		)
+	}
+
+	return nil
+}
+
+// Report the unused npm dependencies once all imports have been resolved.
+func (ts *typeScriptLang) reportUnusedNpmDependencies() {
+	projects := slices.Sorted(maps.Keys(ts.npmDependencyUsage))
+
+	for _, project := range projects {
+		usage := ts.npmDependencyUsage[project]
+		cfg := usage.c.Exts[LanguageName].(*JsGazelleConfig)
+		packageJson := path.Join(project, NpmPackageFilename)
+
+		unused := []string{}
+		for pkg := range ts.pnpmProjects.GetProject(project).Packages() {
+			if !usage.used[pkg] && !isCompilerNpmDependency(pkg) && !cfg.IsUnusedNpmDependencyIgnored(pkg) {
+				unused = append(unused, pkg)
+			}
+		}
+		slices.Sort(unused)
+
+		unusedErrors := make([]error, 0, len(unused))
+		for _, pkg := range unused {
+			unusedErrors = append(unusedErrors, fmt.Errorf(
+				"Package %[1]q declared by %[2]q is an unused dependency not imported by any source. Possible solutions:\n"+
+					"\t1. Remove %[1]q from the dependencies of %[2]q.\n"+
+					"\t2. Ignore the unused dependency using '# aspect:%[3]s %[1]s'\n"+
+					"\t3. Disable Gazelle npm dependency validation using '# aspect:%[4]s off'",
+				pkg, packageJson, Directive_IgnoreUnusedNpmDependencies, Directive_ValidateNpmDependencies,
+			))
+		}
+
+		reportResolutionErrors(usage.c, cfg.ValidateNpmDependencies(), label.New(usage.c.RepoName, project, cfg.npmLinkAllTargetName), unusedErrors)
+	}
+}
+
+// If the npm package is used by the TypeScript compiler rather than imported by
+// sources, such as the compiler itself and @types packages included by default.
+func isCompilerNpmDependency(pkg string) bool {
+	return pkg == "typescript" || pkg == "tslib" || strings.HasPrefix(pkg, "@types/")
+}
@@ -0,0 +1,303 @@
+package gazelle
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	common "github.com/aspect-build/aspect-gazelle/common"
+	"github.com/aspect-build/aspect-gazelle/common/cache"
+	BazelLog "github.com/aspect-build/aspect-gazelle/common/logger"
+	node "github.com/aspect-build/aspect-gazelle/language/js/node"
+	"github.com/bazelbuild/bazel-gazelle/config"
+	"github.com/bazelbuild/bazel-gazelle/label"
+	"github.com/bazelbuild/bazel-gazelle/resolve"
+	"github.com/bazelbuild/bazel-gazelle/walk"
+)
+
+// The files of a package published by `npm pack`, as determined by the package.json
+// "files" and "publishConfig" fields or the .npmignore file, falling back to the
+// .gitignore file when the package has no .npmignore.
+//
+// Only the ignore file at the root of the package is read, the ignore files of
+// nested directories are not supported.
+//
+// See https://docs.npmjs.com/cli/v10/using-npm/developers#keeping-files-out-of-your-package
+
+const NpmIgnoreFilename = ".npmignore"
+const GitIgnoreFilename = ".gitignore"
+
+// Files never published regardless of "files" and .npmignore.
+var npmPackAlwaysExcluded = []string{
+	"**/.npmignore",
+	"**/.gitignore",
+	".npmrc",
+	"package-lock.json",
+	"npm-debug.log",
+	"**/.git/**",
+	"**/node_modules/**",
+	"**/.DS_Store",
+	"**/*.orig",
+}
+
+type npmPackFiles struct {
+	// The patterns of published files, nil if all files are published
+	include []string
+
+	// The patterns of files never published, see npmPackAlwaysExcluded
+	alwaysExclude []string
+
+	// The patterns of files not published by the "files" negations or ignore file
+	exclude []string
+
+	// The package.json "main" and "bin" files, always published
+	entryFiles []string
+
+	// If the published files are restricted by "files" or .npmignore
+	filtered bool
+
+	// The "files" entries matching no file of the package
+	unmatched []string
+
+	includeExpr, excludeExpr common.GlobExpr
+}
+
+// Load the files published by the package in the directory.
+func loadNpmPackFiles(c *config.Config, rel string) (*npmPackFiles, error) {
+	parserCache := cache.Get(c)
+
+	packageJsonPath := path.Join(rel, NpmPackageFilename)
+	publishFiles, _, err := parserCache.LoadOrStoreFile(c.RepoRoot, packageJsonPath, "parsePackageJsonPublishFiles", func(path string, content []byte) (any, error) {
+		return node.ParsePackageJsonPublishFiles(bytes.NewReader(content))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %q files: %w", packageJsonPath, err)
+	}
+
+	files := publishFiles.(node.PackagePublishFiles)
+
+	p := &npmPackFiles{
+		alwaysExclude: npmPackAlwaysExcluded,
+		exclude:       []string{},
+		entryFiles:    files.EntryFiles,
+	}
+
+	if files.Files != nil {
+		// The "files" whitelist, the .npmignore is ignored
+		p.filtered = true
+		p.include = []string{}
+
+		for _, f := range files.Files {
+			f, isNegated := strings.CutPrefix(f, "!")
+			f = strings.Trim(strings.TrimPrefix(f, "./"), "/")
+			if f == "" {
+				continue
+			}
+
+			// Entries match the file or all files within the directory
+			if isNegated {
+				p.exclude = append(p.exclude, f, f+"/**")
+			} else {
+				p.include = append(p.include, f, f+"/**")
+			}
+		}
+	} else if ignoreFilename := npmIgnoreFile(rel); ignoreFilename != "" {
+		ignorePath := path.Join(rel, ignoreFilename)
+		ignores, _, err := parserCache.LoadOrStoreFile(c.RepoRoot, ignorePath, "js.ParseNpmIgnore", func(path string, content []byte) (any, error) {
+			return parseNpmIgnore(content), nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %q: %w", ignorePath, err)
+		}
+
+		p.filtered = true
+		p.exclude = append(p.exclude, ignores.([]string)...)
+	}
+
+	if p.include != nil {
+		if p.includeExpr, err = common.ParseGlobExpressions(p.include); err != nil {
+			return nil, fmt.Errorf("invalid %q files: %w", packageJsonPath, err)
+		}
+	}
+	if p.excludeExpr, err = common.ParseGlobExpressions(slices.Concat(p.alwaysExclude, p.exclude)); err != nil {
+		return nil, fmt.Errorf("invalid %q files: %w", packageJsonPath, err)
+	}
+
+	return p, nil
+}
+
+// The ignore file of the package: the .npmignore or otherwise the .gitignore, like
+// `npm pack`. Empty if the package has neither.
+func npmIgnoreFile(rel string) string {
+	if common.WalkHasPath(rel, NpmIgnoreFilename) {
+		return NpmIgnoreFilename
+	}
+	if common.WalkHasPath(rel, GitIgnoreFilename) {
+		return GitIgnoreFilename
+	}
+	return ""
+}
+
+// Parse the .npmignore (or .gitignore) patterns into globs relative to the package.
+//
+// Negated patterns are not supported and ignored.
+func parseNpmIgnore(content []byte) []string {
+	patterns := []string{}
+	for line := range strings.SplitSeq(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		if line[0] == '!' {
+			BazelLog.Debugf("Negated ignore pattern %q not supported", line)
+			continue
+		}
+
+		// Patterns with no leading or inner "/" match at any depth
+		pattern := strings.TrimSuffix(line, "/")
+		if anchored, isAnchored := strings.CutPrefix(pattern, "/"); isAnchored {
+			pattern = anchored
+		} else if !strings.Contains(pattern, "/") {
+			pattern = "**/" + pattern
+		}
+
+		patterns = append(patterns, pattern, pattern+"/**")
+	}
+	return patterns
+}
+
+// Files published regardless of "files" and .npmignore: the package.json and
+// the top-level README and LICENSE files.
+func isNpmPackAlwaysPublished(f string) bool {
+	if f == NpmPackageFilename {
+		return true
+	}
+
+	if strings.Contains(f, "/") {
+		return false
+	}
+
+	name := strings.ToUpper(strings.TrimSuffix(f, path.Ext(f)))
+	return name == "README" || name == "LICENSE" || name == "LICENCE"
+}
+
+// If the package file is published by `npm pack`.
+func (p *npmPackFiles) isPublished(f string) bool {
+	if isNpmPackAlwaysPublished(f) || slices.Contains(p.entryFiles, f) {
+		return true
+	}
+
+	if p.excludeExpr(f) {
+		return false
+	}
+
+	return p.includeExpr == nil || p.includeExpr(f)
+}
+
+// Record the "files" entries matching none of the package files and not present
+// within a subpackage.
+//
+// Entries of the tsconfig output directories are only produced by the build.
+func (p *npmPackFiles) collectUnmatched(rel string, packageFiles []string, outDirs []string) {
+	if p.include == nil {
+		return
+	}
+
+	// The include patterns are pairs of the entry and the entry directory
+	for i := 0; i < len(p.include); i += 2 {
+		entry := p.include[i]
+
+		expr, err := common.ParseGlobExpressions(p.include[i : i+2])
+		if err != nil || slices.ContainsFunc(packageFiles, expr) || walkHasFileOrDir(path.Join(rel, entry)) {
+			continue
+		}
+
+		isOutDir := slices.ContainsFunc(outDirs, func(outDir string) bool {
+			return entry == outDir || strings.HasPrefix(entry, outDir+"/") || strings.HasPrefix(outDir, entry+"/")
+		})
+		if !isOutDir {
+			p.unmatched = append(p.unmatched, entry)
+		}
+	}
+}
+
+// The include_srcs_patterns of the "files" entries, excluding the unmatched entries.
+func (p *npmPackFiles) includeSrcsPatterns() []string {
+	includes := []string{}
+
+	// The include patterns are pairs of the entry and the entry directory
+	for i := 0; i < len(p.include); i += 2 {
+		if !slices.Contains(p.unmatched, p.include[i]) {
+			includes = append(includes, p.include[i:i+2]...)
+		}
+	}
+	return includes
+}
+
+// The exclude_srcs_patterns of the "files" negations and .npmignore patterns, and the
+// always excluded patterns matching a file of the package.
+func (p *npmPackFiles) excludeSrcsPatterns(packageFiles []string) []string {
+	excludes := []string{}
+	for _, pattern := range p.alwaysExclude {
+		if expr, err := common.ParseGlobExpression(pattern); err == nil && slices.ContainsFunc(packageFiles, expr) {
+			excludes = append(excludes, pattern)
+		}
+	}
+	return append(excludes, p.exclude...)
+}
+
+// If the path is a file or directory of the walked directories.
+func walkHasFileOrDir(p string) bool {
+	d, err := walk.GetDirInfo(path.Dir(p))
+	if err != nil {
+		return false
+	}
+
+	base := path.Base(p)
+	return slices.Contains(d.RegularFiles, base) || slices.Contains(d.Subdirs, base)
+}
+
+// Validate the files referenced by the package.json of an npm package are produced
+// by a Bazel target.
+func (ts *typeScriptLang) validateNpmPackageFiles(c *config.Config, ix *resolve.RuleIndex, from label.Label, packageInfo *TsPackageInfo) {
+	cfg := c.Exts[LanguageName].(*JsGazelleConfig)
+	if cfg.ValidateNpmPackageFiles() == ValidationOff {
+		return
+	}
+
+	packageJsonPath := path.Join(from.Pkg, NpmPackageFilename)
+
+	notProduced := []string{}
+	for it := packageInfo.imports.Iterator(); it.Next(); {
+		imp := it.Value().(ImportStatement)
+		if imp.SourcePath != packageJsonPath {
+			continue
+		}
+
+		resolutionType, _, err := ts.resolveImport(c, ix, from, imp)
+		if err == nil && resolutionType == Resolution_NotFound {
+			notProduced = append(notProduced, imp.ImportPath)
+		}
+	}
+
+	notProduced = append(notProduced, packageInfo.unmatchedFiles...)
+	slices.Sort(notProduced)
+
+	notProducedErrors := make([]error, 0, len(notProduced))
+	for _, p := range slices.Compact(notProduced) {
+		BazelLog.Debugf("path %q of npm package %v not produced by any target", p, from)
+
+		notProducedErrors = append(notProducedErrors, fmt.Errorf(
+			"Path %[1]q referenced by %[2]q is not produced by any Bazel target. Possible solutions:\n"+
+				"\t1. Remove %[1]q from %[2]q.\n"+
+				"\t2. Add a target producing %[1]q.\n"+
+				"\t3. Disable Gazelle npm package files validation using '# aspect:%[3]s off'",
+			p, packageJsonPath, Directive_ValidateNpmPackageFiles,
+		))
+	}
+
+	reportResolutionErrors(c, cfg.ValidateNpmPackageFiles(), from, notProducedErrors)
+}
@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"bytes"
+	"path"
+	"regexp"
+	"strings"
+
+	treeutils "github.com/aspect-build/aspect-gazelle/common/treesitter"
+	"github.com/aspect-build/aspect-gazelle/common/treesitter/grammars/tsx"
+	"github.com/aspect-build/aspect-gazelle/common/treesitter/grammars/typescript"
+)
+
+// Single-file components such as Vue, Svelte and Astro components embed scripts
+// within markup. The scripts are extracted and parsed as TypeScript/JavaScript.
+
+// A script embedded within a component.
+type componentScript struct {
+	lang    treeutils.Language
+	content []byte
+
+	// The file of an external script such as `<script src="./a.ts">`
+	src string
+}
+
+var (
+	htmlCommentRe      = regexp.MustCompile(`(?s)<!--.*?-->`)
+	scriptBlockRe      = regexp.MustCompile(`(?is)<script\b([^>]*)>(.*?)</script\s*>`)
+	scriptAttrRe       = regexp.MustCompile(`(?i)(?:^|\s)(lang|type|src)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	astroFrontmatterRe = regexp.MustCompile(`(?s)\A\s*---\r?\n(.*?)\r?\n---`)
+)
+
+// If the file extension is of a single-file component embedding scripts within markup.
+func IsComponentFileExt(ext string) bool {
+	switch ext {
+	case ".vue", ".svelte", ".astro":
+		return true
+	default:
+		return false
+	}
+}
+
+// Extract the scripts of a component: the Astro frontmatter and the `<script>` blocks
+// of Vue, Svelte and Astro components.
+func extractComponentScripts(filePath string, content []byte) []componentScript {
+	scripts := []componentScript{}
+
+	// Astro component frontmatter, always TypeScript
+	if path.Ext(filePath) == ".astro" {
+		if frontmatter := astroFrontmatterRe.FindSubmatch(content); frontmatter != nil {
+			scripts = append(scripts, componentScript{
+				lang:    typescript.NewLanguage(),
+				content: frontmatter[1],
+			})
+			content = content[len(frontmatter[0]):]
+		}
+	}
+
+	// Ignore scripts within html comments
+	content = htmlCommentRe.ReplaceAllFunc(content, func(comment []byte) []byte {
+		return bytes.Repeat([]byte{' '}, len(comment))
+	})
+
+	for _, block := range scriptBlockRe.FindAllSubmatch(content, -1) {
+		lang, scriptType, src := "", "", ""
+		for _, attr := range scriptAttrRe.FindAllSubmatch(block[1], -1) {
+			value := string(attr[2]) + string(attr[3]) + string(attr[4])
+			switch strings.ToLower(string(attr[1])) {
+			case "lang":
+				lang = strings.ToLower(value)
+			case "type":
+				scriptType = strings.ToLower(value)
+			case "src":
+				src = value
+			}
+		}
+
+		// Non-js scripts such as "application/ld+json" data
+		if !isScriptType(scriptType) {
+			continue
+		}
+
+		if src != "" {
+			// Remote or absolute scripts are not a dependency
+			if !strings.Contains(src, "://") && !strings.HasPrefix(src, "/") {
+				scripts = append(scripts, componentScript{src: src})
+			}
+			continue
+		}
+
+		scripts = append(scripts, componentScript{
+			lang:    scriptLangToLanguage(lang),
+			content: block[2],
+		})
+	}
+
+	return scripts
+}
+
+func isScriptType(scriptType string) bool {
+	switch scriptType {
+	case "", "module", "text/javascript", "application/javascript", "text/typescript", "application/typescript":
+		return true
+	default:
+		return false
+	}
+}
+
+// The script `lang` attribute to language.
+func scriptLangToLanguage(lang string) treeutils.Language {
+	switch lang {
+	case "tsx", "jsx":
+		return tsx.NewLanguage()
+	default:
+		return typescript.NewLanguage()
+	}
+}
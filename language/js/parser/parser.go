@@ -23,6 +23,45 @@ import (
 type ParseResult struct {
 	Imports []string
 	Modules []string
+	Globs   []ImportGlob
+
+	// The names imported from each import only imported by name such as
+	// `import { a } from "./a"`, or "default" for `import b from "./b"`.
+	ImportedNames map[string][]string
+
+	// The names exported by declarations of the file such as `export const a`,
+	// or "default" for the default export.
+	Exports []string
+
+	// The re-exports of other modules such as `export * from "./a"`.
+	ReExports []ReExport
+}
+
+// A re-export of another module such as `export { a as b } from "./a"`.
+type ReExport struct {
+	// The re-exported module
+	From string
+
+	// The exported name, or "*" for all names of `export * from "./a"`.
+	Name string
+
+	// The name within the re-exported module, or "*" for the module namespace
+	// such as `export * as ns from "./a"`.
+	Imported string
+}
+
+// A set of files imported by a single expression such as Vite `import.meta.glob("./pages/*.tsx")`
+// or webpack `require.context("./pages", true, /\.tsx$/)`.
+type ImportGlob struct {
+	// The glob pattern relative to the importing file, or relative to the Dir if set.
+	// Negated patterns starting with "!" exclude files of other globs.
+	Pattern string
+
+	// The directory of a require.context() relative to the importing file
+	Dir string
+
+	// A regular expression filtering files by the path relative to the Dir such as "./a/b.tsx"
+	Filter string
 }
 
 type ParseErrors struct {
@@ -45,6 +84,13 @@ func (pe *ParseErrors) Error() string {
 // - from: a string representing an imported resource such as a name or path
 // - triple-slash: a triple-slash directive comment
 // - defined: a string representing a defined module name
+// - glob: a glob pattern of imported files
+// - context-dir, context-recursive, context-filter: the arguments of a require.context()
+// - import-clause, import-clause-from: the imported names of an import statement
+// - export-name: a name exported by a declaration
+// - export-default: the default export
+// - export-clause: the names of a local export such as `export { a, b as c }`
+// - reexport-clause, reexport-all, reexport-namespace, reexport-from: a re-export of another module
 const importsQuery = `
 	(call_expression
 		function: [
@@ -56,6 +102,67 @@ const importsQuery = `
 		(#eq? @equals-require "require")
 	)
 
+	(call_expression
+		function: (member_expression
+			object: (identifier) @equals-require
+			property: (property_identifier) @equals-resolve
+		)
+		arguments: (arguments . (string (string_fragment) @from))
+
+		(#eq? @equals-require "require")
+		(#eq? @equals-resolve "resolve")
+	)
+
+	(new_expression
+		constructor: (identifier) @equals-url
+		arguments: (arguments
+			. (string (string_fragment) @from)
+			. (member_expression
+				object: (member_expression
+					object: (import)
+					property: (property_identifier) @equals-meta
+				)
+				property: (property_identifier) @equals-meta-url
+			)
+		)
+
+		(#eq? @equals-url "URL")
+		(#eq? @equals-meta "meta")
+		(#eq? @equals-meta-url "url")
+	)
+
+	(call_expression
+		function: (member_expression
+			object: (member_expression
+				object: (import)
+				property: (property_identifier) @equals-meta
+			)
+			property: (property_identifier) @equals-glob
+		)
+		arguments: (arguments . [
+			(string (string_fragment) @glob)
+			(array (string (string_fragment) @glob))
+		])
+
+		(#eq? @equals-meta "meta")
+		(#match? @equals-glob "^glob(Eager)?$")
+	)
+
+	(call_expression
+		function: (member_expression
+			object: (identifier) @equals-require
+			property: (property_identifier) @equals-context
+		)
+		arguments: (arguments
+			. (string (string_fragment) @context-dir)
+			. ([(true) (false)] @context-recursive)?
+			. (regex pattern: (regex_pattern) @context-filter)?
+		)
+
+		(#eq? @equals-require "require")
+		(#eq? @equals-context "context")
+	)
+
 	(program
 		(import_statement
 			source: (string (string_fragment) @from)
@@ -68,6 +175,61 @@ const importsQuery = `
 		)
 	)
 
+	(program
+		(import_statement
+			(import_clause) @import-clause
+			source: (string (string_fragment) @import-clause-from)
+		)
+	)
+
+	(program
+		(export_statement
+			declaration: [
+				(function_declaration name: (_) @export-name)
+				(generator_function_declaration name: (_) @export-name)
+				(class_declaration name: (_) @export-name)
+				(abstract_class_declaration name: (_) @export-name)
+				(interface_declaration name: (_) @export-name)
+				(type_alias_declaration name: (_) @export-name)
+				(enum_declaration name: (_) @export-name)
+				(lexical_declaration (variable_declarator name: (identifier) @export-name))
+				(variable_declaration (variable_declarator name: (identifier) @export-name))
+			]
+		)
+	)
+
+	(program
+		(export_statement "default" @export-default)
+	)
+
+	(program
+		(export_statement
+			(export_clause) @export-clause
+			!source
+		)
+	)
+
+	(program
+		(export_statement
+			(export_clause) @reexport-clause
+			source: (string (string_fragment) @reexport-from)
+		)
+	)
+
+	(program
+		(export_statement
+			"*" @reexport-all
+			source: (string (string_fragment) @reexport-from)
+		)
+	)
+
+	(program
+		(export_statement
+			(namespace_export (_) @reexport-namespace)
+			source: (string (string_fragment) @reexport-from)
+		)
+	)
+
 	(program
 		(comment) @triple-slash
 		(#match? @triple-slash "^///\\s*<reference\\s+(?:path|types)\\s*=")
@@ -103,65 +265,228 @@ const importsQuery = `
 var tripleSlashRe = regexp.MustCompile(`^///\s*<reference\s+(?:path|types)\s*=\s*"(?P<lib>[^"]+)"`)
 
 func ParseSource(filePath string, sourceCode []byte) (ParseResult, error) {
-	var imports []string
-	var modules []string
+	result := ParseResult{}
 	var errs []error
 
-	lang := filenameToLanguage(filePath)
+	if IsComponentFileExt(path.Ext(filePath)) {
+		// Components such as .vue files embedding scripts within markup
+		for _, script := range extractComponentScripts(filePath, sourceCode) {
+			if script.src != "" {
+				result.Imports = append(result.Imports, script.src)
+			} else if err := parseScript(filePath, script.lang, script.content, &result); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	} else if err := parseScript(filePath, filenameToLanguage(filePath), sourceCode, &result); err != nil {
+		errs = append(errs, err)
+	}
 
+	var perr error
+	if len(errs) > 0 {
+		perr = &ParseErrors{errs}
+	}
+
+	return result, perr
+}
+
+// Parse the script source code and add the imports and modules to the result.
+func parseScript(filePath string, lang treeutils.Language, sourceCode []byte, result *ParseResult) error {
 	// Parse the source code
 	tree, err := treeutils.ParseSourceCode(lang, filePath, sourceCode)
-	if err != nil {
-		errs = append(errs, err)
+	if tree == nil {
+		return err
 	}
+	defer tree.Close()
 
-	if tree != nil {
-		defer tree.Close()
+	// The import statements importing names of the file
+	importClauses := []importClause{}
 
-		// Query for more complex non-root node imports.
-		q, err := treeutils.GetQuery(lang, importsQuery)
-		if err != nil {
-			log.Fatalf("Failed to create js 'importsQuery': %v", err)
-		}
-		for queryResult := range tree.Query(q) {
-			Log.Tracef("AST Query %q: %v", filePath, queryResult)
-
-			caps := queryResult.Captures()
-			if from, isFrom := caps["from"]; isFrom {
-				imports = append(imports, from)
-			} else if tripSlash, isTripSlash := caps["triple-slash"]; isTripSlash {
-				// Parse triple-slash directives
-				if lib, ok := getTripleSlashDirectiveModule(tripSlash); ok {
-					imports = append(imports, lib)
-				}
-			} else if defined, isDefined := caps["defined"]; isDefined {
-				modules = append(modules, defined)
-			} else {
-				log.Fatalf("Unexpected query result for %q: %v", filePath, queryResult)
+	// Query for more complex non-root node imports.
+	q, qerr := treeutils.GetQuery(lang, importsQuery)
+	if qerr != nil {
+		log.Fatalf("Failed to create js 'importsQuery': %v", qerr)
+	}
+	for queryResult := range tree.Query(q) {
+		Log.Tracef("AST Query %q: %v", filePath, queryResult)
+
+		caps := queryResult.Captures()
+		if from, isFrom := caps["from"]; isFrom {
+			result.Imports = append(result.Imports, from)
+		} else if tripSlash, isTripSlash := caps["triple-slash"]; isTripSlash {
+			// Parse triple-slash directives
+			if lib, ok := getTripleSlashDirectiveModule(tripSlash); ok {
+				result.Imports = append(result.Imports, lib)
+			}
+		} else if defined, isDefined := caps["defined"]; isDefined {
+			result.Modules = append(result.Modules, defined)
+		} else if glob, isGlob := caps["glob"]; isGlob {
+			result.Globs = append(result.Globs, ImportGlob{Pattern: glob})
+		} else if dir, isContext := caps["context-dir"]; isContext {
+			result.Globs = append(result.Globs, toRequireContextGlob(dir, caps["context-recursive"], caps["context-filter"]))
+		} else if clause, isImportClause := caps["import-clause"]; isImportClause {
+			importClauses = append(importClauses, importClause{from: caps["import-clause-from"], clause: clause})
+		} else if name, isExportName := caps["export-name"]; isExportName {
+			result.Exports = append(result.Exports, name)
+		} else if _, isExportDefault := caps["export-default"]; isExportDefault {
+			result.Exports = append(result.Exports, "default")
+		} else if clause, isExportClause := caps["export-clause"]; isExportClause {
+			specs, _ := parseClauseSpecifiers(clause)
+			for _, spec := range specs {
+				result.Exports = append(result.Exports, spec[1])
 			}
+		} else if clause, isReExportClause := caps["reexport-clause"]; isReExportClause {
+			specs, _ := parseClauseSpecifiers(clause)
+			for _, spec := range specs {
+				result.ReExports = append(result.ReExports, ReExport{From: caps["reexport-from"], Name: spec[1], Imported: spec[0]})
+			}
+		} else if _, isReExportAll := caps["reexport-all"]; isReExportAll {
+			result.ReExports = append(result.ReExports, ReExport{From: caps["reexport-from"], Name: "*", Imported: "*"})
+		} else if ns, isReExportNamespace := caps["reexport-namespace"]; isReExportNamespace {
+			result.ReExports = append(result.ReExports, ReExport{From: caps["reexport-from"], Name: ns, Imported: "*"})
+		} else {
+			log.Fatalf("Unexpected query result for %q: %v", filePath, queryResult)
 		}
+	}
 
-		// Parse errors. Only log them due to many false positives potentially caused by issues
-		// such as only parsing a single file at a time so type information from other files is missing.
-		if Log.IsLevelEnabled(Log.TraceLevel) {
-			treeErrors := tree.QueryErrors()
-			if treeErrors != nil {
-				Log.Tracef("TreeSitter query errors: %v", treeErrors)
-			}
+	addImportedNames(result, importClauses)
+
+	// Parse errors. Only log them due to many false positives potentially caused by issues
+	// such as only parsing a single file at a time so type information from other files is missing.
+	if Log.IsLevelEnabled(Log.TraceLevel) {
+		treeErrors := tree.QueryErrors()
+		if treeErrors != nil {
+			Log.Tracef("TreeSitter query errors: %v", treeErrors)
 		}
 	}
 
-	result := ParseResult{
-		Imports: imports,
-		Modules: modules,
+	return err
+}
+
+// The clause of an import statement such as `a, { b as c }` of `import a, { b as c } from "./a"`.
+type importClause struct {
+	from, clause string
+}
+
+// Add the names imported from imports only imported by name.
+//
+// Namespace imports, side-effect imports, require() and other imports may use
+// any export of the imported module.
+func addImportedNames(result *ParseResult, importClauses []importClause) {
+	namedImports := make(map[string][]string)
+	namedImportCounts := make(map[string]int)
+	for _, ic := range importClauses {
+		names, isNamed := parseImportClauseNames(ic.clause)
+		if !isNamed {
+			continue
+		}
+		namedImports[ic.from] = append(namedImports[ic.from], names...)
+		namedImportCounts[ic.from]++
 	}
 
-	var perr error
-	if len(errs) > 0 {
-		perr = &ParseErrors{errs}
+	// Imports only imported by name
+	for _, imp := range result.Imports {
+		namedImportCounts[imp]--
+	}
+	for from, names := range namedImports {
+		if namedImportCounts[from] != 0 {
+			continue
+		}
+		if result.ImportedNames == nil {
+			result.ImportedNames = make(map[string][]string)
+		}
+		result.ImportedNames[from] = append(result.ImportedNames[from], names...)
 	}
+}
 
-	return result, perr
+// Parse the names of an import clause such as `a, { b as c, type d }`, or
+// return false for namespace imports such as `* as ns`.
+func parseImportClauseNames(clause string) ([]string, bool) {
+	clause = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(clause), "type "))
+
+	names := []string{}
+
+	// The default import before any named imports
+	if !strings.HasPrefix(clause, "{") {
+		defaultImport, rest, _ := strings.Cut(clause, ",")
+		if strings.HasPrefix(strings.TrimSpace(defaultImport), "*") {
+			return nil, false
+		}
+		names = append(names, "default")
+		clause = strings.TrimSpace(rest)
+	}
+
+	if strings.HasPrefix(clause, "*") {
+		return nil, false
+	}
+
+	specs, ok := parseClauseSpecifiers(clause)
+	if !ok {
+		return nil, false
+	}
+	for _, spec := range specs {
+		names = append(names, spec[0])
+	}
+
+	return names, true
+}
+
+// Parse the specifiers of a named import or export clause such as `{ a, b as c, type d }`
+// to the [local, exported] names, or return false if the clause is not understood.
+func parseClauseSpecifiers(clause string) ([][2]string, bool) {
+	clause = clauseCommentRe.ReplaceAllString(clause, " ")
+	clause = strings.TrimSpace(clause)
+	clause = strings.TrimSuffix(strings.TrimPrefix(clause, "{"), "}")
+
+	specs := [][2]string{}
+	for _, spec := range strings.Split(clause, ",") {
+		fields := strings.Fields(spec)
+		if len(fields) > 1 && fields[0] == "type" && fields[1] != "as" {
+			fields = fields[1:]
+		}
+
+		switch {
+		case len(fields) == 0:
+			// Trailing commas
+		case len(fields) == 1:
+			specs = append(specs, [2]string{unquoteName(fields[0]), unquoteName(fields[0])})
+		case len(fields) == 3 && fields[1] == "as":
+			specs = append(specs, [2]string{unquoteName(fields[0]), unquoteName(fields[2])})
+		default:
+			return nil, false
+		}
+	}
+	return specs, true
+}
+
+var clauseCommentRe = regexp.MustCompile(`(?s)/\*.*?\*/|//[^\n]*`)
+
+// Unquote string names such as `export { a as "a-b" }`.
+func unquoteName(name string) string {
+	if len(name) >= 2 && (name[0] == '"' || name[0] == '\'') {
+		return name[1 : len(name)-1]
+	}
+	return name
+}
+
+// Convert the arguments of a webpack require.context(directory, useSubdirectories, regExp)
+// with the same defaults as webpack.
+//
+// See https://webpack.js.org/guides/dependency-management/#requirecontext
+func toRequireContextGlob(dir, recursive, filter string) ImportGlob {
+	pattern := "**/*"
+	if recursive == "false" {
+		pattern = "*"
+	}
+
+	if filter == "" {
+		filter = `^\.\/.*$`
+	}
+
+	return ImportGlob{
+		Dir:     dir,
+		Pattern: pattern,
+		Filter:  filter,
+	}
 }
 
 // Extract the module name out of a triple-slash directive comment.
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -9,6 +10,7 @@ var testCases = []struct {
 	filename        string
 	expectedImports []string
 	expectedModules []string
+	expectedGlobs   []ImportGlob
 }{
 	{
 		desc:     "empty",
@@ -320,6 +322,142 @@ var testCases = []struct {
 		expectedModules: []string{"https://mod.com"},
 		expectedImports: []string{"ftp://ancient.com"},
 	},
+	{
+		desc: "new URL with import.meta.url",
+		ts: `
+			const worker = new Worker(new URL('./worker.ts', import.meta.url), { type: 'module' });
+			const wasm = new URL("../lib/module.wasm", import.meta.url);
+			const other = new URL('./not-a-dep', 'https://example.com');
+			const meta = new URL(import.meta.url);
+		`,
+		filename:        "url.ts",
+		expectedImports: []string{"./worker.ts", "../lib/module.wasm"},
+	},
+	{
+		desc: "require.resolve",
+		ts: `
+			const p = require.resolve('some-package/package.json');
+			const q = other.resolve('not-a-dep');
+		`,
+		filename:        "resolve.js",
+		expectedImports: []string{"some-package/package.json"},
+	},
+	{
+		desc: "import.meta.glob",
+		ts: `
+			const pages = import.meta.glob('./pages/*.tsx');
+			const eager = import.meta.globEager("./eager/**/*.ts");
+			const multi = import.meta.glob(['./a/*.ts', '!./a/skip.ts'], { eager: true });
+			const notGlob = import.meta.other('./x.ts');
+		`,
+		filename: "glob.ts",
+		expectedGlobs: []ImportGlob{
+			{Pattern: "./pages/*.tsx"},
+			{Pattern: "./eager/**/*.ts"},
+			{Pattern: "./a/*.ts"},
+			{Pattern: "!./a/skip.ts"},
+		},
+	},
+	{
+		desc: "require.context",
+		ts: `
+			const all = require.context('./components');
+			const flat = require.context('./icons', false);
+			const filtered = require.context('./pages', true, /\.tsx$/);
+		`,
+		filename: "context.js",
+		expectedGlobs: []ImportGlob{
+			{Dir: "./components", Pattern: "**/*", Filter: `^\.\/.*$`},
+			{Dir: "./icons", Pattern: "*", Filter: `^\.\/.*$`},
+			{Dir: "./pages", Pattern: "**/*", Filter: `\.tsx$`},
+		},
+	},
+	{
+		desc: "vue component",
+		ts: `
+			<template>
+				<Child :msg="msg" />
+			</template>
+
+			<!-- <script>import 'commented'</script> -->
+
+			<script lang="ts">
+				import { defineComponent } from 'vue'
+				export default defineComponent({})
+			</script>
+
+			<script setup lang="ts">
+				import Child from './Child.vue'
+				const msg = await import('./msg')
+			</script>
+
+			<style scoped>
+				.a { color: red; }
+			</style>
+		`,
+		filename:        "App.vue",
+		expectedImports: []string{"vue", "./Child.vue", "./msg"},
+	},
+	{
+		desc: "vue component external script",
+		ts: `
+			<template><div /></template>
+			<script src="./component.ts"></script>
+			<script src="https://cdn.example.com/lib.js"></script>
+		`,
+		filename:        "External.vue",
+		expectedImports: []string{"./component.ts"},
+	},
+	{
+		desc: "vue component tsx",
+		ts: `
+			<script lang="tsx">
+				import { render } from './render'
+				export default { render: () => <div>{render()}</div> }
+			</script>
+		`,
+		filename:        "Tsx.vue",
+		expectedImports: []string{"./render"},
+	},
+	{
+		desc: "svelte component",
+		ts: `
+			<script context="module" lang="ts">
+				export { preload } from './preload'
+			</script>
+
+			<script>
+				import { onMount } from 'svelte'
+				import Nested from './Nested.svelte'
+			</script>
+
+			<script type="application/ld+json">
+				{"@context": "https://schema.org"}
+			</script>
+
+			<Nested />
+		`,
+		filename:        "Page.svelte",
+		expectedImports: []string{"./preload", "svelte", "./Nested.svelte"},
+	},
+	{
+		desc: "astro component",
+		ts: `---
+import Layout from '../layouts/Layout.astro';
+import { getCollection } from 'astro:content';
+const posts = await getCollection('blog');
+---
+<Layout>
+	<script>
+		import { init } from '../scripts/init';
+		init();
+	</script>
+	<script is:inline src="/analytics.js"></script>
+</Layout>
+`,
+		filename:        "index.astro",
+		expectedImports: []string{"../layouts/Layout.astro", "astro:content", "../scripts/init"},
+	},
 }
 
 func RunParserTests(t *testing.T, parserPost string) {
@@ -349,6 +487,109 @@ func TestTreesitterParser(t *testing.T) {
 			if !equal(res.Modules, tc.expectedModules) {
 				t.Errorf("Unexpected module results\nactual:  %#v;\nexpected: %#v\ntypescript code:\n%v", res.Modules, tc.expectedModules, tc.ts)
 			}
+
+			if !reflect.DeepEqual(res.Globs, tc.expectedGlobs) {
+				t.Errorf("Unexpected glob results\nactual:  %#v;\nexpected: %#v\ntypescript code:\n%v", res.Globs, tc.expectedGlobs, tc.ts)
+			}
+		})
+	}
+}
+
+var exportsTestCases = []struct {
+	desc, ts              string
+	filename              string
+	expectedImportedNames map[string][]string
+	expectedExports       []string
+	expectedReExports     []ReExport
+}{
+	{
+		desc: "imported names",
+		ts: `
+			import React, { useState as useS, type FC } from "react";
+			import type { A } from "./a";
+			import { /* comment */ b, // comment
+				c,
+			} from "./bc";
+			import d from "./d";
+		`,
+		filename: "names.ts",
+		expectedImportedNames: map[string][]string{
+			"react": {"default", "useState", "FC"},
+			"./a":   {"A"},
+			"./bc":  {"b", "c"},
+			"./d":   {"default"},
+		},
+	},
+	{
+		desc: "imports using the whole module",
+		ts: `
+			import * as ns from "./ns";
+			import def, * as ns2 from "./ns2";
+			import { a } from "./side-effect";
+			import "./side-effect";
+			import { b } from "./required";
+			const r = require("./required");
+			import { c } from "./c";
+		`,
+		filename: "whole.ts",
+		expectedImportedNames: map[string][]string{
+			"./c": {"c"},
+		},
+	},
+	{
+		desc: "exports",
+		ts: `
+			export const a = 1, b = 2;
+			export let c;
+			export function d() {}
+			export class E {}
+			export abstract class F {}
+			export interface G {}
+			export type H = string;
+			export enum I {}
+			const j = 1, k = 2;
+			export { j, k as K };
+			export default j;
+		`,
+		filename:        "exports.ts",
+		expectedExports: []string{"a", "b", "c", "d", "E", "F", "G", "H", "I", "j", "K", "default"},
+	},
+	{
+		desc: "re-exports",
+		ts: `
+			export * from "./all";
+			export * as ns from "./ns";
+			export { a, b as B, default as C } from "./abc";
+			export type { T } from "./types";
+		`,
+		filename: "index.ts",
+		expectedReExports: []ReExport{
+			{From: "./all", Name: "*", Imported: "*"},
+			{From: "./ns", Name: "ns", Imported: "*"},
+			{From: "./abc", Name: "a", Imported: "a"},
+			{From: "./abc", Name: "B", Imported: "b"},
+			{From: "./abc", Name: "C", Imported: "default"},
+			{From: "./types", Name: "T", Imported: "T"},
+		},
+	},
+}
+
+func TestParseExports(t *testing.T) {
+	for _, tc := range exportsTestCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			res, _ := ParseSource(tc.filename, []byte(tc.ts))
+
+			if !reflect.DeepEqual(res.ImportedNames, tc.expectedImportedNames) {
+				t.Errorf("Unexpected imported names\nactual:  %#v;\nexpected: %#v\ntypescript code:\n%v", res.ImportedNames, tc.expectedImportedNames, tc.ts)
+			}
+
+			if !equal(res.Exports, tc.expectedExports) {
+				t.Errorf("Unexpected exports\nactual:  %#v;\nexpected: %#v\ntypescript code:\n%v", res.Exports, tc.expectedExports, tc.ts)
+			}
+
+			if !equal(res.ReExports, tc.expectedReExports) {
+				t.Errorf("Unexpected re-exports\nactual:  %#v;\nexpected: %#v\ntypescript code:\n%v", res.ReExports, tc.expectedReExports, tc.ts)
+			}
 		})
 	}
 }
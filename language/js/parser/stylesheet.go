@@ -0,0 +1,223 @@
+package parser
+
+import (
+	"path"
+	"strings"
+)
+
+// Find the dependencies of stylesheets such as CSS, SCSS and LESS files.
+//
+// Dependencies are the @import, @use and @forward rules and url() references.
+// Comments and strings are skipped, references to remote or inline resources
+// such as "https://..." or "data:..." are excluded.
+func ParseStylesheet(filePath string, content []byte) (ParseResult, error) {
+	s := stylesheetScanner{
+		content: content,
+		// SCSS, Sass and LESS support '//' line comments
+		lineComments: isPreprocessedStylesheet(filePath),
+	}
+
+	for s.i < len(s.content) {
+		c := s.content[s.i]
+
+		switch {
+		case c == '/' && s.peek(1) == '*':
+			s.skipBlockComment()
+		case c == '/' && s.peek(1) == '/' && s.lineComments:
+			s.skipLineComment()
+		case c == '"' || c == '\'':
+			s.readString()
+		case c == '@':
+			s.i++
+			switch strings.ToLower(s.readIdent()) {
+			case "import", "use", "forward":
+				s.readImportRule()
+			}
+		case s.isURLStart():
+			s.addImport(s.readURL())
+		default:
+			s.i++
+		}
+	}
+
+	return ParseResult{Imports: s.imports}, nil
+}
+
+func isPreprocessedStylesheet(filePath string) bool {
+	switch path.Ext(filePath) {
+	case ".scss", ".sass", ".less":
+		return true
+	default:
+		return false
+	}
+}
+
+type stylesheetScanner struct {
+	content      []byte
+	i            int
+	lineComments bool
+	imports      []string
+}
+
+func (s *stylesheetScanner) peek(offset int) byte {
+	if s.i+offset < len(s.content) {
+		return s.content[s.i+offset]
+	}
+	return 0
+}
+
+func (s *stylesheetScanner) skipWhitespace() {
+	for s.i < len(s.content) && strings.IndexByte(" \t\r\n\f", s.content[s.i]) >= 0 {
+		s.i++
+	}
+}
+
+func (s *stylesheetScanner) skipBlockComment() {
+	end := strings.Index(string(s.content[s.i+2:]), "*/")
+	if end == -1 {
+		s.i = len(s.content)
+	} else {
+		s.i += 2 + end + 2
+	}
+}
+
+func (s *stylesheetScanner) skipLineComment() {
+	for s.i < len(s.content) && s.content[s.i] != '\n' {
+		s.i++
+	}
+}
+
+func (s *stylesheetScanner) readIdent() string {
+	start := s.i
+	for s.i < len(s.content) && isIdentChar(s.content[s.i]) {
+		s.i++
+	}
+	return string(s.content[start:s.i])
+}
+
+func isIdentChar(c byte) bool {
+	return c == '-' || c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// Read a quoted string starting at the current quote character.
+func (s *stylesheetScanner) readString() string {
+	quote := s.content[s.i]
+	s.i++
+
+	var value strings.Builder
+	for s.i < len(s.content) {
+		c := s.content[s.i]
+		s.i++
+
+		if c == quote {
+			break
+		}
+		if c == '\\' && s.i < len(s.content) {
+			c = s.content[s.i]
+			s.i++
+		}
+		value.WriteByte(c)
+	}
+	return value.String()
+}
+
+// If the current position is the start of a url() function, not part of another identifier.
+func (s *stylesheetScanner) isURLStart() bool {
+	if s.i+4 > len(s.content) || !strings.EqualFold(string(s.content[s.i:s.i+4]), "url(") {
+		return false
+	}
+	return s.i == 0 || !isIdentChar(s.content[s.i-1])
+}
+
+// Read a url() function starting at the "url(" prefix.
+func (s *stylesheetScanner) readURL() string {
+	s.i += len("url(")
+	s.skipWhitespace()
+
+	var value string
+	if c := s.peek(0); c == '"' || c == '\'' {
+		value = s.readString()
+	} else {
+		start := s.i
+		for s.i < len(s.content) && s.content[s.i] != ')' {
+			s.i++
+		}
+		value = strings.TrimSpace(string(s.content[start:s.i]))
+	}
+
+	// The closing ')'
+	for s.i < len(s.content) && s.content[s.i] != ')' {
+		s.i++
+	}
+	s.i++
+
+	return value
+}
+
+// Read the comma separated list of strings or url()s of an @import, @use or @forward rule.
+func (s *stylesheetScanner) readImportRule() {
+	for {
+		s.skipWhitespace()
+
+		// LESS import options such as "@import (reference) 'foo';"
+		if s.peek(0) == '(' {
+			for s.i < len(s.content) && s.content[s.i] != ')' {
+				s.i++
+			}
+			s.i++
+			s.skipWhitespace()
+		}
+
+		if c := s.peek(0); c == '"' || c == '\'' {
+			s.addImport(s.readString())
+		} else if s.isURLStart() {
+			s.addImport(s.readURL())
+		} else {
+			return
+		}
+
+		s.skipWhitespace()
+		if s.peek(0) != ',' {
+			return
+		}
+		s.i++
+	}
+}
+
+func (s *stylesheetScanner) addImport(url string) {
+	// Strip any query or fragment such as "font.woff2?v=1#iefix"
+	if end := strings.IndexAny(url, "?#"); end > 0 {
+		url = url[:end]
+	}
+
+	if !isStylesheetDependency(url) {
+		return
+	}
+
+	s.imports = append(s.imports, url)
+}
+
+// If the url references a file that is a dependency of the stylesheet.
+func isStylesheetDependency(url string) bool {
+	// Empty, fragments, absolute paths and protocol-relative urls
+	if url == "" || url[0] == '#' || url[0] == '/' {
+		return false
+	}
+
+	// Remote resources of any protocol
+	if strings.Contains(url, "://") {
+		return false
+	}
+
+	// Inline resources and built-in modules such as "data:..." or "sass:math"
+	if colon := strings.IndexByte(url, ':'); colon > 0 && !strings.ContainsAny(url[:colon], "./") {
+		return false
+	}
+
+	// Interpolated values such as "#{$path}/a.png", "$var" or "@{var}"
+	if strings.Contains(url, "#{") || strings.Contains(url, "@{") || url[0] == '$' {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"testing"
+)
+
+var stylesheetTestCases = []struct {
+	desc, css       string
+	filename        string
+	expectedImports []string
+}{
+	{
+		desc:     "empty",
+		css:      "",
+		filename: "empty.css",
+	}, {
+		desc: "import string",
+		css: `
+			@import "a.css";
+			@import './b.css' screen;
+		`,
+		filename:        "import.css",
+		expectedImports: []string{"a.css", "./b.css"},
+	}, {
+		desc: "import url",
+		css: `
+			@import url("a.css");
+			@import url(./b.css) print;
+			@IMPORT url( 'c.css' );
+		`,
+		filename:        "import-url.css",
+		expectedImports: []string{"a.css", "./b.css", "c.css"},
+	}, {
+		desc: "url references",
+		css: `
+			.logo { background: url(./logo.svg) no-repeat; }
+			@font-face {
+				font-family: "Foo";
+				src: url("../fonts/foo.woff2?v=1") format("woff2"), url('../fonts/foo.woff#iefix');
+			}
+		`,
+		filename:        "urls.css",
+		expectedImports: []string{"./logo.svg", "../fonts/foo.woff2", "../fonts/foo.woff"},
+	}, {
+		desc: "remote and inline urls",
+		css: `
+			@import url("https://fonts.googleapis.com/css?family=Roboto");
+			@import "//cdn.example.com/a.css";
+			.a { background: url(data:image/png;base64,iVBORw0KGgo=); }
+			.b { background: url(/absolute.png); }
+			.c { filter: url(#svg-filter); }
+		`,
+		filename: "remote.css",
+	}, {
+		desc: "comments and strings",
+		css: `
+			/* @import "commented.css"; url(commented.png) */
+			.a::before { content: "url(not-a-url.png)"; }
+			.b { background: url(./b.png); }
+		`,
+		filename:        "comments.css",
+		expectedImports: []string{"./b.png"},
+	}, {
+		desc: "identifiers ending in url",
+		css: `
+			.a { mask: foo-url(a.png); background: url(b.png); }
+		`,
+		filename:        "ident.css",
+		expectedImports: []string{"b.png"},
+	}, {
+		desc: "scss use and forward",
+		css: `
+			@use "sass:math";
+			@use 'variables' as vars;
+			@forward "src/list" hide list-reset;
+			// @import "commented";
+			@import 'a', 'b';
+			.a { background: url(#{$base}/a.png); }
+		`,
+		filename:        "main.scss",
+		expectedImports: []string{"variables", "src/list", "a", "b"},
+	}, {
+		desc: "line comments only in preprocessed stylesheets",
+		css: `
+			.a { background: url(//cdn.example.com/a.png); }
+			.b { background: url(./b.png); }
+		`,
+		filename:        "protocol.css",
+		expectedImports: []string{"./b.png"},
+	}, {
+		desc: "less import options",
+		css: `
+			@import (reference) "foo.less";
+			@import (css, optional) 'bar';
+			.a { background: url("@{base}/a.png"); }
+		`,
+		filename:        "main.less",
+		expectedImports: []string{"foo.less", "bar"},
+	},
+}
+
+func TestParseStylesheet(t *testing.T) {
+	for _, tc := range stylesheetTestCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			res, _ := ParseStylesheet(tc.filename, []byte(tc.css))
+
+			if !equal(res.Imports, tc.expectedImports) {
+				t.Errorf("Unexpected import results\nactual:  %#v;\nexpected: %#v\nstylesheet:\n%v", res.Imports, tc.expectedImports, tc.css)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package parser
+
+import (
+	"log"
+	"path"
+	"strings"
+
+	Log "github.com/aspect-build/aspect-gazelle/common/logger"
+	treeutils "github.com/aspect-build/aspect-gazelle/common/treesitter"
+	"github.com/aspect-build/aspect-gazelle/common/treesitter/grammars/typescript"
+	"gopkg.in/yaml.v3"
+)
+
+// The configuration of a test runner such as jest, vitest or mocha relevant
+// to the dependencies of the tests.
+type TestRunnerConfig struct {
+	// Files or packages loaded before the tests such as the jest `setupFiles`,
+	// vitest `setupFiles` or mocha `require`.
+	SetupFiles []string
+
+	// The jest `moduleNameMapper` in declaration order.
+	ModuleNameMapper []ModuleNameMapping
+}
+
+// A jest `moduleNameMapper` entry.
+type ModuleNameMapping struct {
+	// A regular expression matching import paths
+	Pattern string
+
+	// The path or package replacing matching imports, may reference capture groups such as "$1"
+	Target string
+}
+
+// A query finding the setup files and module aliases within test runner configuration.
+//
+// Query matches may include captures:
+// - setup: a string of a setup file or package
+// - pattern, target: a jest moduleNameMapper entry
+const testRunnerConfigQuery = `
+	(pair
+		key: [(property_identifier) (string)] @setup-key
+		value: [
+			(string) @setup
+			(array (string) @setup)
+		]
+
+		(#match? @setup-key "^[\"']?(setupFiles|setupFilesAfterEnv|globalSetup|globalTeardown|require|file)[\"']?$")
+	)
+
+	(pair
+		key: [(property_identifier) (string)] @mapper-key
+		value: (object
+			(pair
+				key: (string) @pattern
+				value: [
+					(string) @target
+					(array . (string) @target)
+				]
+			)
+		)
+
+		(#match? @mapper-key "^[\"']?moduleNameMapper[\"']?$")
+	)
+`
+
+// Parse a test runner configuration file such as jest.config.js, vitest.config.ts
+// or .mocharc.yml.
+//
+// Only literal values are found, computed values within js configuration are ignored.
+func ParseTestRunnerConfig(filePath string, content []byte) (TestRunnerConfig, error) {
+	switch path.Ext(filePath) {
+	case ".yaml", ".yml":
+		return parseYamlTestRunnerConfig(content)
+	case ".json", ".jsonc":
+		// Parse json as a js expression to support comments
+		content = append([]byte("export default "), content...)
+		filePath = strings.TrimSuffix(filePath, path.Ext(filePath)) + ".js"
+	}
+
+	result := TestRunnerConfig{}
+
+	lang := typescript.NewLanguage()
+	tree, err := treeutils.ParseSourceCode(lang, filePath, content)
+	if tree == nil {
+		return result, err
+	}
+	defer tree.Close()
+
+	q, qerr := treeutils.GetQuery(lang, testRunnerConfigQuery)
+	if qerr != nil {
+		log.Fatalf("Failed to create js 'testRunnerConfigQuery': %v", qerr)
+	}
+	for queryResult := range tree.Query(q) {
+		Log.Tracef("AST Query %q: %v", filePath, queryResult)
+
+		caps := queryResult.Captures()
+		if setup, isSetup := caps["setup"]; isSetup {
+			result.SetupFiles = append(result.SetupFiles, unquoteString(setup))
+		} else if pattern, isPattern := caps["pattern"]; isPattern {
+			result.ModuleNameMapper = append(result.ModuleNameMapper, ModuleNameMapping{
+				Pattern: unquoteString(pattern),
+				Target:  unquoteString(caps["target"]),
+			})
+		} else {
+			log.Fatalf("Unexpected query result for %q: %v", filePath, queryResult)
+		}
+	}
+
+	return result, err
+}
+
+// The mocha yaml configuration where each option may be a string or list of strings.
+type mocharcYaml struct {
+	Require yaml.Node `yaml:"require"`
+	File    yaml.Node `yaml:"file"`
+}
+
+func parseYamlTestRunnerConfig(content []byte) (TestRunnerConfig, error) {
+	var rc mocharcYaml
+	if err := yaml.Unmarshal(content, &rc); err != nil {
+		return TestRunnerConfig{}, err
+	}
+
+	result := TestRunnerConfig{}
+	for _, n := range []*yaml.Node{&rc.Require, &rc.File} {
+		switch n.Kind {
+		case yaml.ScalarNode:
+			result.SetupFiles = append(result.SetupFiles, n.Value)
+		case yaml.SequenceNode:
+			for _, item := range n.Content {
+				if item.Kind == yaml.ScalarNode {
+					result.SetupFiles = append(result.SetupFiles, item.Value)
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// The value of a quoted js string literal such as `"a\\.css"`.
+func unquoteString(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') {
+		s = s[1 : len(s)-1]
+	}
+
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+
+	var value strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n':
+				c = '\n'
+			case 't':
+				c = '\t'
+			default:
+				c = s[i]
+			}
+		}
+		value.WriteByte(c)
+	}
+	return value.String()
+}
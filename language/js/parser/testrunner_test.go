@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testRunnerConfigTestCases = []struct {
+	desc, config           string
+	filename               string
+	expectedSetupFiles     []string
+	expectedModuleMappings []ModuleNameMapping
+}{
+	{
+		desc:     "empty",
+		config:   "",
+		filename: "jest.config.js",
+	}, {
+		desc: "jest js",
+		config: `
+			module.exports = {
+				testEnvironment: 'node',
+				setupFiles: ['<rootDir>/setup.ts'],
+				setupFilesAfterEnv: ["jest-extended/all", './test/matchers.ts'],
+				moduleNameMapper: {
+					'^@/(.*)$': '<rootDir>/src/$1',
+					"\\.(css|less)$": "identity-obj-proxy",
+					'^lodash$': ['lodash-es', 'lodash'],
+				},
+			};
+		`,
+		filename:           "jest.config.js",
+		expectedSetupFiles: []string{"<rootDir>/setup.ts", "jest-extended/all", "./test/matchers.ts"},
+		expectedModuleMappings: []ModuleNameMapping{
+			{Pattern: "^@/(.*)$", Target: "<rootDir>/src/$1"},
+			{Pattern: `\.(css|less)$`, Target: "identity-obj-proxy"},
+			{Pattern: "^lodash$", Target: "lodash-es"},
+		},
+	}, {
+		desc: "jest ts",
+		config: `
+			import type { Config } from 'jest';
+			const config: Config = {
+				globalSetup: './global-setup.ts',
+			};
+			export default config;
+		`,
+		filename:           "jest.config.ts",
+		expectedSetupFiles: []string{"./global-setup.ts"},
+	}, {
+		desc: "jest json",
+		config: `{
+			// comments are allowed
+			"setupFiles": ["./setup.js"],
+			"moduleNameMapper": {"^~/(.*)$": "<rootDir>/$1"}
+		}`,
+		filename:               "jest.config.json",
+		expectedSetupFiles:     []string{"./setup.js"},
+		expectedModuleMappings: []ModuleNameMapping{{Pattern: "^~/(.*)$", Target: "<rootDir>/$1"}},
+	}, {
+		desc: "vitest",
+		config: `
+			import { defineConfig } from 'vitest/config';
+			export default defineConfig({
+				test: {
+					environment: 'jsdom',
+					setupFiles: ['./vitest.setup.ts'],
+				},
+			});
+		`,
+		filename:           "vitest.config.ts",
+		expectedSetupFiles: []string{"./vitest.setup.ts"},
+	}, {
+		desc: "mocha json",
+		config: `{
+			"require": "ts-node/register",
+			"file": ["./test/setup.js"],
+			"spec": "test/**/*.spec.ts"
+		}`,
+		filename:           ".mocharc.json",
+		expectedSetupFiles: []string{"ts-node/register", "./test/setup.js"},
+	}, {
+		desc: "mocha yaml",
+		config: `
+require:
+  - ts-node/register
+  - ./test/setup.ts
+file: ./test/global.ts
+spec: test/**/*.spec.ts
+`,
+		filename:           ".mocharc.yml",
+		expectedSetupFiles: []string{"ts-node/register", "./test/setup.ts", "./test/global.ts"},
+	},
+}
+
+func TestParseTestRunnerConfig(t *testing.T) {
+	for _, tc := range testRunnerConfigTestCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			res, err := ParseTestRunnerConfig(tc.filename, []byte(tc.config))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !equal(res.SetupFiles, tc.expectedSetupFiles) {
+				t.Errorf("Unexpected setup files\nactual:  %#v;\nexpected: %#v\nconfig:\n%v", res.SetupFiles, tc.expectedSetupFiles, tc.config)
+			}
+
+			if (len(res.ModuleNameMapper) > 0 || len(tc.expectedModuleMappings) > 0) && !reflect.DeepEqual(res.ModuleNameMapper, tc.expectedModuleMappings) {
+				t.Errorf("Unexpected moduleNameMapper\nactual:  %#v;\nexpected: %#v\nconfig:\n%v", res.ModuleNameMapper, tc.expectedModuleMappings, tc.config)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package gazelle
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// bun text-based bun.lock lockfiles.
+//
+// See https://bun.sh/docs/install/lockfile
+type bunLockfile struct{}
+
+type bunLockWorkspace struct {
+	Name                 string            `json:"name"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+type bunLock struct {
+	LockfileVersion int                         `json:"lockfileVersion"`
+	Workspaces      map[string]bunLockWorkspace `json:"workspaces"`
+
+	// Package entries are arrays where the first element is the "name@version" resolution
+	Packages map[string][]any `json:"packages"`
+}
+
+func (bunLockfile) ParseDependencies(lockfileDir string, lockfileContent []byte) (WorkspacePackageVersionMap, error) {
+	if len(lockfileContent) == 0 {
+		return nil, nil
+	}
+
+	lock := bunLock{}
+	if err := json.Unmarshal(stripTrailingCommas(lockfileContent), &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse bun.lock: %w", err)
+	}
+
+	// Workspace projects by package name for resolving workspace references
+	workspaceProjects := make(map[string]string, len(lock.Workspaces))
+	for project, ws := range lock.Workspaces {
+		if ws.Name != "" {
+			workspaceProjects[ws.Name] = project
+		}
+	}
+
+	result := make(WorkspacePackageVersionMap)
+
+	for project, ws := range lock.Workspaces {
+		deps := mergeDependencies(ws.PeerDependencies, ws.OptionalDependencies, ws.DevDependencies, ws.Dependencies)
+
+		versions := make(map[string]string, len(deps))
+		for name, spec := range deps {
+			versions[name] = lock.resolveVersion(project, ws.Name, name, spec, workspaceProjects)
+		}
+
+		result[normalizeLockfileProject(project)] = versions
+	}
+
+	return result, nil
+}
+
+func (lock bunLock) resolveVersion(project, projectName, name, spec string, workspaceProjects map[string]string) string {
+	// Packages specific to a workspace are keyed by the workspace name, hoisted packages by the package name.
+	for _, key := range []string{projectName + "/" + name, name} {
+		entry, found := lock.Packages[key]
+		if !found || len(entry) == 0 {
+			continue
+		}
+
+		resolution, isString := entry[0].(string)
+		if !isString {
+			continue
+		}
+
+		_, version := splitPackageDescriptor(resolution)
+		if target, isWorkspace := strings.CutPrefix(version, "workspace:"); isWorkspace {
+			return toLinkVersion(project, target)
+		}
+		return version
+	}
+
+	if strings.HasPrefix(spec, "workspace:") {
+		if target, found := workspaceProjects[name]; found {
+			return toLinkVersion(project, target)
+		}
+	}
+
+	return spec
+}
+
+// stripTrailingCommas removes the trailing commas bun writes after the last object member
+// and array element to produce standard JSON.
+//
+// Note jsonr is not used due to the "" key of the root workspace.
+func stripTrailingCommas(content []byte) []byte {
+	result := make([]byte, 0, len(content))
+
+	inString := false
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+
+		if inString {
+			if c == '\\' && i+1 < len(content) {
+				result = append(result, c, content[i+1])
+				i++
+				continue
+			}
+			inString = c != '"'
+		} else if c == '"' {
+			inString = true
+		} else if c == ',' {
+			// Drop the comma if the next non-whitespace character closes the object or array
+			j := i + 1
+			for j < len(content) && strings.IndexByte(" \t\r\n", content[j]) >= 0 {
+				j++
+			}
+			if j < len(content) && (content[j] == '}' || content[j] == ']') {
+				continue
+			}
+		}
+
+		result = append(result, c)
+	}
+
+	return result
+}
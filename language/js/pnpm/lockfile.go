@@ -0,0 +1,88 @@
+package gazelle
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Lockfile parses a package manager lockfile into the packages available to each workspace project.
+//
+// Implementations normalize local workspace packages to pnpm-style `link:` versions relative
+// to the project so all lockfile formats populate a PnpmProjectMap the same way.
+type Lockfile interface {
+	// Parse the lockfile content and return a map of workspace projects to a map of dependency name to version.
+	//
+	// The lockfileDir is the absolute path of the directory containing the lockfile and may be used
+	// to read workspace files not recorded in the lockfile itself.
+	ParseDependencies(lockfileDir string, lockfileContent []byte) (WorkspacePackageVersionMap, error)
+}
+
+// WorkspaceFilesLockfile is a Lockfile also reading workspace files other than the lockfile
+// when parsing dependencies, the parsed dependencies must not be cached by the lockfile
+// content alone.
+type WorkspaceFilesLockfile interface {
+	Lockfile
+
+	// The absolute paths of the workspace files read when parsing the lockfile content in the lockfileDir.
+	WorkspaceFiles(lockfileDir string, lockfileContent []byte) ([]string, error)
+}
+
+// GetLockfile returns the Lockfile implementation for the lockfile at the given path.
+//
+// The format is determined by the lockfile name, defaulting to pnpm for unknown names.
+func GetLockfile(lockfilePath string) Lockfile {
+	switch path.Base(lockfilePath) {
+	case "package-lock.json", "npm-shrinkwrap.json":
+		return npmLockfile{}
+	case "yarn.lock":
+		return yarnLockfile{}
+	case "bun.lock":
+		return bunLockfile{}
+	}
+
+	return pnpmLockfile{}
+}
+
+type pnpmLockfile struct{}
+
+func (pnpmLockfile) ParseDependencies(lockfileDir string, lockfileContent []byte) (WorkspacePackageVersionMap, error) {
+	return ParsePnpmLockFileDependencies(lockfileContent)
+}
+
+// normalizeLockfileProject converts a lockfile workspace path such as "" or "./packages/a"
+// to the project format used by the WorkspacePackageVersionMap.
+func normalizeLockfileProject(project string) string {
+	if project == "" {
+		return "."
+	}
+	return path.Clean(project)
+}
+
+// toLinkVersion returns a pnpm-style `link:` version from the project to a local workspace
+// package, both relative to the lockfile directory.
+func toLinkVersion(project, target string) string {
+	link, err := filepath.Rel(normalizeLockfileProject(project), normalizeLockfileProject(target))
+	if err != nil {
+		return "link:" + target
+	}
+	return "link:" + filepath.ToSlash(link)
+}
+
+// splitPackageDescriptor splits a "name@range" descriptor, supporting scoped package names.
+func splitPackageDescriptor(descriptor string) (string, string) {
+	if i := strings.Index(descriptor[min(1, len(descriptor)):], "@"); i >= 0 {
+		return descriptor[:i+1], descriptor[i+2:]
+	}
+	return descriptor, ""
+}
+
+func mergeDependencies(d ...map[string]string) map[string]string {
+	deps := make(map[string]string)
+	for _, m := range d {
+		for k, v := range m {
+			deps[k] = v
+		}
+	}
+	return deps
+}
@@ -0,0 +1,337 @@
+package gazelle
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGetLockfile(t *testing.T) {
+	for lockfilePath, expected := range map[string]Lockfile{
+		"pnpm-lock.yaml":          pnpmLockfile{},
+		"sub/custom-lock.yaml":    pnpmLockfile{},
+		"package-lock.json":       npmLockfile{},
+		"sub/npm-shrinkwrap.json": npmLockfile{},
+		"yarn.lock":               yarnLockfile{},
+		"sub/bun.lock":            bunLockfile{},
+	} {
+		if actual := GetLockfile(lockfilePath); actual != expected {
+			t.Errorf("GetLockfile(%q): expected %T, got %T", lockfilePath, expected, actual)
+		}
+	}
+}
+
+func TestToLinkVersion(t *testing.T) {
+	for _, c := range []struct{ project, target, expected string }{
+		{"", "packages/a", "link:packages/a"},
+		{".", "./packages/a", "link:packages/a"},
+		{"packages/b", "packages/a", "link:../a"},
+		{"apps/x/y", "packages/a", "link:../../../packages/a"},
+		{"packages/a", ".", "link:../.."},
+	} {
+		if actual := toLinkVersion(c.project, c.target); actual != c.expected {
+			t.Errorf("toLinkVersion(%q, %q): expected %q, got %q", c.project, c.target, c.expected, actual)
+		}
+	}
+}
+
+func TestSplitPackageDescriptor(t *testing.T) {
+	for descriptor, expected := range map[string][2]string{
+		"lodash@^4.17.21":          {"lodash", "^4.17.21"},
+		"@scope/pkg@npm:1.0.0":     {"@scope/pkg", "npm:1.0.0"},
+		"@scope/pkg@workspace:a/b": {"@scope/pkg", "workspace:a/b"},
+		"alias@npm:@scope/pkg@1":   {"alias", "npm:@scope/pkg@1"},
+		"lodash":                   {"lodash", ""},
+	} {
+		name, version := splitPackageDescriptor(descriptor)
+		if name != expected[0] || version != expected[1] {
+			t.Errorf("splitPackageDescriptor(%q): expected %v, got [%s %s]", descriptor, expected, name, version)
+		}
+	}
+}
+
+func assertWorkspacePackageVersions(t *testing.T, actual, expected WorkspacePackageVersionMap, err error) {
+	t.Helper()
+
+	if err != nil {
+		t.Fatal("Parse failure: ", err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Unexpected workspace packages.\nExpected: %v\nActual:   %v", expected, actual)
+	}
+}
+
+func TestNpmLockParseDependencies(t *testing.T) {
+	t.Run("empty lock file", func(t *testing.T) {
+		emptyLock, err := npmLockfile{}.ParseDependencies("", []byte{})
+		assertWorkspacePackageVersions(t, emptyLock, nil, err)
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		_, err := npmLockfile{}.ParseDependencies("", []byte(`{"lockfileVersion": 1, "dependencies": {}}`))
+		if err == nil {
+			t.Error("Expected error for unsupported version (1)")
+		}
+	})
+
+	t.Run("workspaces (lockfile v3)", func(t *testing.T) {
+		lock, err := npmLockfile{}.ParseDependencies("", []byte(`{
+  "name": "root",
+  "lockfileVersion": 3,
+  "requires": true,
+  "packages": {
+    "": {
+      "name": "root",
+      "workspaces": ["packages/*"],
+      "dependencies": {"jquery": "^3.6.0"},
+      "devDependencies": {"@aspect-test/c": "^2.0.0"}
+    },
+    "node_modules/jquery": {"version": "3.6.1"},
+    "node_modules/@aspect-test/c": {"version": "2.0.2"},
+    "node_modules/@aspect-test/a": {"version": "5.0.2"},
+    "node_modules/@lib/a": {"resolved": "packages/a", "link": true},
+    "packages/a": {
+      "name": "@lib/a",
+      "version": "1.0.0",
+      "dependencies": {"@aspect-test/a": "^5.0.0", "jquery": "^2.0.0"}
+    },
+    "packages/a/node_modules/jquery": {"version": "2.2.4"},
+    "packages/b": {
+      "name": "@lib/b",
+      "version": "1.0.0",
+      "dependencies": {"@lib/a": "*", "missing": "^1.0.0"}
+    }
+  }
+}`))
+
+		assertWorkspacePackageVersions(t, lock, WorkspacePackageVersionMap{
+			".": {
+				"jquery":         "3.6.1",
+				"@aspect-test/c": "2.0.2",
+			},
+			"packages/a": {
+				"@aspect-test/a": "5.0.2",
+				"jquery":         "2.2.4",
+			},
+			"packages/b": {
+				"@lib/a":  "link:../a",
+				"missing": "^1.0.0",
+			},
+		}, err)
+	})
+}
+
+func TestYarnLockParseDependencies(t *testing.T) {
+	t.Run("empty lock file", func(t *testing.T) {
+		emptyLock, err := yarnLockfile{}.ParseDependencies("", []byte("\n"))
+		assertWorkspacePackageVersions(t, emptyLock, nil, err)
+	})
+
+	t.Run("workspaces (berry)", func(t *testing.T) {
+		lock, err := yarnLockfile{}.ParseDependencies("", []byte(`# This file is generated by running "yarn install" inside your project.
+# Manual changes might be lost - proceed with caution!
+
+__metadata:
+  version: 8
+  cacheKey: 10c0
+
+"@aspect-test/a@npm:^5.0.0":
+  version: 5.0.2
+  resolution: "@aspect-test/a@npm:5.0.2"
+  languageName: node
+  linkType: hard
+
+"@lib/a@workspace:*, @lib/a@workspace:packages/a":
+  version: 0.0.0-use.local
+  resolution: "@lib/a@workspace:packages/a"
+  dependencies:
+    "@aspect-test/a": "npm:^5.0.0"
+    jquery: ^3.6.0
+  languageName: unknown
+  linkType: soft
+
+"jquery@npm:^3.6.0, jquery@npm:^3.6.1":
+  version: 3.6.1
+  resolution: "jquery@npm:3.6.1"
+  languageName: node
+  linkType: hard
+
+"root@workspace:.":
+  version: 0.0.0-use.local
+  resolution: "root@workspace:."
+  dependencies:
+    "@lib/a": "workspace:*"
+    jquery: ^3.6.1
+  languageName: unknown
+  linkType: soft
+`))
+
+		assertWorkspacePackageVersions(t, lock, WorkspacePackageVersionMap{
+			".": {
+				"@lib/a": "link:packages/a",
+				"jquery": "3.6.1",
+			},
+			"packages/a": {
+				"@aspect-test/a": "5.0.2",
+				"jquery":         "3.6.1",
+			},
+		}, err)
+	})
+
+	t.Run("workspaces (classic)", func(t *testing.T) {
+		root := t.TempDir()
+		writeTestFile(t, root, "package.json", `{
+  "name": "root",
+  "private": true,
+  "workspaces": {"packages": ["packages/*"]},
+  "devDependencies": {"jquery": "^3.6.0"}
+}`)
+		writeTestFile(t, root, "packages/a/package.json", `{
+  "name": "@lib/a",
+  "dependencies": {"@aspect-test/a": "^5.0.0", "@lib/b": "1.0.0"}
+}`)
+		writeTestFile(t, root, "packages/b/package.json", `{
+  "name": "@lib/b",
+  "version": "1.0.0",
+}`)
+
+		lockContent := []byte(`# THIS IS AN AUTOGENERATED FILE. DO NOT EDIT THIS FILE DIRECTLY.
+# yarn lockfile v1
+
+
+"@aspect-test/a@^5.0.0":
+  version "5.0.2"
+  resolved "https://registry.yarnpkg.com/@aspect-test/a/-/a-5.0.2.tgz"
+  dependencies:
+    jquery "^2.0.0"
+
+jquery@^2.0.0:
+  version "2.2.4"
+
+jquery@^3.6.0, jquery@^3.6.1:
+  version "3.6.1"
+`)
+
+		lock, err := yarnLockfile{}.ParseDependencies(root, lockContent)
+
+		assertWorkspacePackageVersions(t, lock, WorkspacePackageVersionMap{
+			".": {
+				"jquery": "3.6.1",
+			},
+			"packages/a": {
+				"@aspect-test/a": "5.0.2",
+				"@lib/b":         "link:../b",
+			},
+			"packages/b": {},
+		}, err)
+
+		files, err := yarnLockfile{}.WorkspaceFiles(root, lockContent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectedFiles := []string{
+			filepath.Join(root, "package.json"),
+			filepath.Join(root, "packages/a/package.json"),
+			filepath.Join(root, "packages/b/package.json"),
+		}
+		if !reflect.DeepEqual(files, expectedFiles) {
+			t.Errorf("Expected workspace files %v, got %v", expectedFiles, files)
+		}
+	})
+}
+
+func TestYarnWorkspaceProjects(t *testing.T) {
+	t.Run("doublestar and negated patterns", func(t *testing.T) {
+		root := t.TempDir()
+		writeTestFile(t, root, "package.json", `{
+  "name": "root",
+  "workspaces": ["packages/**", "!packages/excluded", "./tools/*"]
+}`)
+		writeTestFile(t, root, "packages/a/package.json", `{"name": "a"}`)
+		writeTestFile(t, root, "packages/nested/b/package.json", `{"name": "b"}`)
+		writeTestFile(t, root, "packages/excluded/package.json", `{"name": "excluded"}`)
+		writeTestFile(t, root, "packages/a/node_modules/dep/package.json", `{"name": "dep"}`)
+		writeTestFile(t, root, "tools/c/package.json", `{"name": "c"}`)
+
+		projects, err := findYarnWorkspaceProjects(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := map[string]string{
+			".":                 filepath.Join(root, "package.json"),
+			"packages/a":        filepath.Join(root, "packages/a/package.json"),
+			"packages/nested/b": filepath.Join(root, "packages/nested/b/package.json"),
+			"tools/c":           filepath.Join(root, "tools/c/package.json"),
+		}
+		if !reflect.DeepEqual(projects, expected) {
+			t.Errorf("Expected workspace projects %v, got %v", expected, projects)
+		}
+	})
+
+	t.Run("berry lockfiles read no workspace files", func(t *testing.T) {
+		files, err := yarnLockfile{}.WorkspaceFiles(t.TempDir(), []byte("__metadata:\n  version: 6\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 0 {
+			t.Errorf("Expected no workspace files, got %v", files)
+		}
+	})
+}
+
+func TestBunLockParseDependencies(t *testing.T) {
+	t.Run("empty lock file", func(t *testing.T) {
+		emptyLock, err := bunLockfile{}.ParseDependencies("", []byte{})
+		assertWorkspacePackageVersions(t, emptyLock, nil, err)
+	})
+
+	t.Run("workspaces", func(t *testing.T) {
+		lock, err := bunLockfile{}.ParseDependencies("", []byte(`{
+  "lockfileVersion": 1,
+  "workspaces": {
+    "": {
+      "name": "root",
+      "dependencies": {
+        "@lib/a": "workspace:*",
+        "jquery": "^3.6.0",
+      },
+    },
+    "packages/a": {
+      "name": "@lib/a",
+      "devDependencies": {
+        "jquery": "^2.0.0",
+      },
+    },
+  },
+  "packages": {
+    "@lib/a": ["@lib/a@workspace:packages/a"],
+    "jquery": ["jquery@3.6.1", "", {}, "sha512-abc"],
+    "@lib/a/jquery": ["jquery@2.2.4", "", {}, "sha512-def"],
+  }
+}`))
+
+		assertWorkspacePackageVersions(t, lock, WorkspacePackageVersionMap{
+			".": {
+				"@lib/a": "link:packages/a",
+				"jquery": "3.6.1",
+			},
+			"packages/a": {
+				"jquery": "2.2.4",
+			},
+		}, err)
+	})
+}
+
+func writeTestFile(t *testing.T, root, name, content string) {
+	t.Helper()
+
+	p := filepath.Join(root, name)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
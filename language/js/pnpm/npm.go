@@ -0,0 +1,96 @@
+package gazelle
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// npm package-lock.json and npm-shrinkwrap.json lockfiles.
+//
+// See https://docs.npmjs.com/cli/configuring-npm/package-lock-json
+type npmLockfile struct{}
+
+type npmLockPackage struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Resolved             string            `json:"resolved"`
+	Link                 bool              `json:"link"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+type npmPackageLock struct {
+	LockfileVersion int                       `json:"lockfileVersion"`
+	Packages        map[string]npmLockPackage `json:"packages"`
+}
+
+func (npmLockfile) ParseDependencies(lockfileDir string, lockfileContent []byte) (WorkspacePackageVersionMap, error) {
+	if len(lockfileContent) == 0 {
+		return nil, nil
+	}
+
+	lock := npmPackageLock{}
+	if err := json.Unmarshal(lockfileContent, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse package-lock.json: %w", err)
+	}
+
+	// Only v2+ lockfiles contain the "packages" section describing each workspace project
+	if lock.LockfileVersion < 2 {
+		return nil, fmt.Errorf("unsupported package-lock.json version: %v", lock.LockfileVersion)
+	}
+
+	result := make(WorkspacePackageVersionMap)
+
+	for key, pkg := range lock.Packages {
+		// Installed packages are within a node_modules directory, everything else is a workspace project
+		if isNpmInstalledPackage(key) {
+			continue
+		}
+
+		project := normalizeLockfileProject(key)
+		deps := mergeDependencies(pkg.PeerDependencies, pkg.OptionalDependencies, pkg.DevDependencies, pkg.Dependencies)
+
+		versions := make(map[string]string, len(deps))
+		for name, spec := range deps {
+			versions[name] = lock.resolveVersion(key, name, spec)
+		}
+
+		result[project] = versions
+	}
+
+	return result, nil
+}
+
+func isNpmInstalledPackage(key string) bool {
+	return key == "node_modules" || strings.HasPrefix(key, "node_modules/") || strings.Contains(key, "/node_modules/")
+}
+
+// resolveVersion finds the version of a package installed for a project using the
+// node_modules resolution algorithm: the closest node_modules walking up from the project.
+func (lock npmPackageLock) resolveVersion(project, name, spec string) string {
+	for dir := project; ; dir = path.Dir(dir) {
+		if dir == "." {
+			dir = ""
+		}
+
+		if installed, found := lock.Packages[path.Join(dir, "node_modules", name)]; found {
+			if installed.Link {
+				return toLinkVersion(project, installed.Resolved)
+			}
+			if installed.Version != "" {
+				return installed.Version
+			}
+		}
+
+		if dir == "" {
+			break
+		}
+	}
+
+	// Fallback to the version specifier if the package is not installed
+	return spec
+}
@@ -1,7 +1,9 @@
 package gazelle
 
 import (
+	"iter"
 	"log"
+	"maps"
 	"path"
 	"strings"
 
@@ -176,6 +178,22 @@ func (p *PnpmProject) Parent() *PnpmProject {
 	return pp
 }
 
+// The packages declared by this project, excluding packages of parent projects.
+func (p *PnpmProject) Packages() iter.Seq[string] {
+	return maps.Keys(p.packages)
+}
+
+// The nearest project declaring the package, either this project or a parent project.
+func (p *PnpmProject) GetDeclaringProject(pkg string) *PnpmProject {
+	for pkgProject := p; pkgProject != nil; pkgProject = pkgProject.Parent() {
+		if pkgProject.packages[pkg] != nil {
+			return pkgProject
+		}
+	}
+
+	return nil
+}
+
 func (p *PnpmProject) Get(pkg string) *label.Label {
 	for pkgProject := p; pkgProject != nil; {
 		if found := pkgProject.packages[pkg]; found != nil {
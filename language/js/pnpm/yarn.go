@@ -0,0 +1,328 @@
+package gazelle
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/msolo/jsonr"
+	"gopkg.in/yaml.v3"
+)
+
+// yarn.lock lockfiles of both yarn classic (v1) and yarn berry (v2+).
+//
+// See https://classic.yarnpkg.com/lang/en/docs/yarn-lock/
+type yarnLockfile struct{}
+
+var _ WorkspaceFilesLockfile = yarnLockfile{}
+
+// Yarn classic lockfiles do not record the workspace projects, the package.json
+// files of the workspace projects are read when parsing the lockfile.
+// Berry lockfiles record the workspace projects and read no other files.
+func (yarnLockfile) WorkspaceFiles(lockfileDir string, lockfileContent []byte) ([]string, error) {
+	if len(bytes.TrimSpace(lockfileContent)) == 0 || isYarnBerryLockfile(lockfileContent) {
+		return nil, nil
+	}
+
+	projectFiles, err := findYarnWorkspaceProjects(lockfileDir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := slices.Collect(maps.Values(projectFiles))
+	slices.Sort(files)
+	return files, nil
+}
+
+func (yarnLockfile) ParseDependencies(lockfileDir string, lockfileContent []byte) (WorkspacePackageVersionMap, error) {
+	if len(bytes.TrimSpace(lockfileContent)) == 0 {
+		return nil, nil
+	}
+
+	if isYarnBerryLockfile(lockfileContent) {
+		return parseYarnBerryLockDependencies(lockfileContent)
+	}
+
+	return parseYarnClassicLockDependencies(lockfileDir, lockfileContent)
+}
+
+// Berry lockfiles are YAML with a __metadata entry, classic lockfiles are a custom format.
+func isYarnBerryLockfile(lockfileContent []byte) bool {
+	return bytes.Contains(lockfileContent, []byte("\n__metadata:")) || bytes.HasPrefix(lockfileContent, []byte("__metadata:"))
+}
+
+// Yarn Berry ----------------------------------------------------------
+
+type yarnBerryLockEntry struct {
+	Version          string            `yaml:"version"`
+	Resolution       string            `yaml:"resolution"`
+	Dependencies     map[string]string `yaml:"dependencies"`
+	PeerDependencies map[string]string `yaml:"peerDependencies"`
+}
+
+func parseYarnBerryLockDependencies(lockfileContent []byte) (WorkspacePackageVersionMap, error) {
+	entries := make(map[string]yarnBerryLockEntry)
+	if err := yaml.Unmarshal(lockfileContent, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse yarn.lock: %w", err)
+	}
+
+	// Index the entries by each descriptor, and the workspace projects by package name
+	descriptors := make(map[string]yarnBerryLockEntry, len(entries))
+	workspaceProjects := make(map[string]string)
+	workspaceEntries := make(map[string]yarnBerryLockEntry)
+
+	for key, entry := range entries {
+		if key == "__metadata" {
+			continue
+		}
+
+		for descriptor := range strings.SplitSeq(key, ",") {
+			descriptors[strings.TrimSpace(descriptor)] = entry
+		}
+
+		name, reference := splitPackageDescriptor(entry.Resolution)
+		if project, isWorkspace := strings.CutPrefix(reference, "workspace:"); isWorkspace {
+			workspaceProjects[name] = project
+			workspaceEntries[project] = entry
+		}
+	}
+
+	result := make(WorkspacePackageVersionMap)
+
+	for project, entry := range workspaceEntries {
+		// Workspace entries list both dependencies and devDependencies as "dependencies"
+		deps := mergeDependencies(entry.PeerDependencies, entry.Dependencies)
+
+		versions := make(map[string]string, len(deps))
+		for name, spec := range deps {
+			versions[name] = resolveYarnBerryVersion(project, name, spec, descriptors, workspaceProjects)
+		}
+
+		result[normalizeLockfileProject(project)] = versions
+	}
+
+	return result, nil
+}
+
+func resolveYarnBerryVersion(project, name, spec string, descriptors map[string]yarnBerryLockEntry, workspaceProjects map[string]string) string {
+	if strings.HasPrefix(spec, "workspace:") {
+		if target, found := workspaceProjects[name]; found {
+			return toLinkVersion(project, target)
+		}
+	}
+
+	// Ranges without a protocol are recorded with the default npm: protocol
+	for _, descriptor := range []string{name + "@" + spec, name + "@npm:" + spec} {
+		if entry, found := descriptors[descriptor]; found {
+			_, reference := splitPackageDescriptor(entry.Resolution)
+			if target, isWorkspace := strings.CutPrefix(reference, "workspace:"); isWorkspace {
+				return toLinkVersion(project, target)
+			}
+			return entry.Version
+		}
+	}
+
+	return spec
+}
+
+// Yarn Classic ----------------------------------------------------------
+
+type yarnPackageJSON struct {
+	Name                 string            `json:"name"`
+	Workspaces           any               `json:"workspaces"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+// parseYarnClassicLockDependencies parses a yarn classic lockfile.
+//
+// Classic lockfiles do not record the workspace projects or their direct dependencies,
+// those are read from the package.json files of the workspace.
+func parseYarnClassicLockDependencies(lockfileDir string, lockfileContent []byte) (WorkspacePackageVersionMap, error) {
+	descriptors, err := parseYarnClassicLockVersions(lockfileContent)
+	if err != nil {
+		return nil, err
+	}
+
+	projectFiles, err := findYarnWorkspaceProjects(lockfileDir)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make(map[string]*yarnPackageJSON, len(projectFiles))
+	for project, packageJsonPath := range projectFiles {
+		pkg, err := readYarnPackageJSON(packageJsonPath)
+		if err != nil {
+			return nil, err
+		}
+		projects[project] = pkg
+	}
+
+	workspaceProjects := make(map[string]string, len(projects))
+	for project, pkg := range projects {
+		if pkg.Name != "" {
+			workspaceProjects[pkg.Name] = project
+		}
+	}
+
+	result := make(WorkspacePackageVersionMap)
+
+	for project, pkg := range projects {
+		deps := mergeDependencies(pkg.PeerDependencies, pkg.OptionalDependencies, pkg.DevDependencies, pkg.Dependencies)
+
+		versions := make(map[string]string, len(deps))
+		for name, spec := range deps {
+			if target, isWorkspace := workspaceProjects[name]; isWorkspace {
+				versions[name] = toLinkVersion(project, target)
+			} else if version, found := descriptors[name+"@"+spec]; found {
+				versions[name] = version
+			} else {
+				versions[name] = spec
+			}
+		}
+
+		result[project] = versions
+	}
+
+	return result, nil
+}
+
+// parseYarnClassicLockVersions returns a map of "name@range" descriptors to the resolved version.
+func parseYarnClassicLockVersions(lockfileContent []byte) (map[string]string, error) {
+	descriptors := make(map[string]string)
+
+	var current []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(lockfileContent))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		// Unindented lines start a new entry with a list of descriptors
+		if line[0] != ' ' {
+			current = current[:0]
+			for descriptor := range strings.SplitSeq(strings.TrimSuffix(trimmed, ":"), ",") {
+				current = append(current, strings.Trim(strings.TrimSpace(descriptor), `"`))
+			}
+			continue
+		}
+
+		if version, isVersion := strings.CutPrefix(trimmed, "version "); isVersion && len(line)-len(strings.TrimLeft(line, " ")) == 2 {
+			for _, descriptor := range current {
+				descriptors[descriptor] = strings.Trim(version, `"`)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to parse yarn.lock: %w", err)
+	}
+
+	return descriptors, nil
+}
+
+// findYarnWorkspaceProjects returns the package.json path of each yarn workspace project,
+// including the root project ".".
+//
+// Workspace patterns are globs supporting "**", patterns prefixed with "!" exclude
+// the projects matched by any other pattern.
+func findYarnWorkspaceProjects(lockfileDir string) (map[string]string, error) {
+	rootPackageJson := path.Join(lockfileDir, "package.json")
+	root, err := readYarnPackageJSON(rootPackageJson)
+	if err != nil {
+		return nil, err
+	}
+
+	var includes, excludes []string
+	for _, pattern := range getYarnWorkspacePatterns(root.Workspaces) {
+		negated := strings.HasPrefix(pattern, "!")
+		pattern = path.Clean(strings.TrimPrefix(strings.TrimPrefix(pattern, "!"), "./"))
+
+		if !doublestar.ValidatePattern(pattern) {
+			return nil, fmt.Errorf("invalid yarn workspace pattern %q", pattern)
+		}
+
+		if negated {
+			excludes = append(excludes, pattern)
+		} else {
+			includes = append(includes, pattern)
+		}
+	}
+
+	projects := map[string]string{".": rootPackageJson}
+	lockfileFS := os.DirFS(lockfileDir)
+
+	for _, pattern := range includes {
+		matches, err := doublestar.Glob(lockfileFS, path.Join(pattern, "package.json"), doublestar.WithFilesOnly())
+		if err != nil {
+			return nil, fmt.Errorf("invalid yarn workspace pattern %q: %w", pattern, err)
+		}
+
+		for _, match := range matches {
+			project := path.Dir(match)
+			if project == "." || isYarnExcludedProject(project, excludes) {
+				continue
+			}
+			projects[project] = filepath.Join(lockfileDir, filepath.FromSlash(match))
+		}
+	}
+
+	return projects, nil
+}
+
+func isYarnExcludedProject(project string, excludes []string) bool {
+	if slices.Contains(strings.Split(project, "/"), "node_modules") {
+		return true
+	}
+	return slices.ContainsFunc(excludes, func(exclude string) bool {
+		return doublestar.MatchUnvalidated(exclude, project)
+	})
+}
+
+func readYarnPackageJSON(packageJsonPath string) (*yarnPackageJSON, error) {
+	content, err := os.ReadFile(packageJsonPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read yarn workspace package.json: %w", err)
+	}
+
+	pkg := &yarnPackageJSON{}
+	if err := jsonr.Unmarshal(content, pkg); err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", packageJsonPath, err)
+	}
+
+	return pkg, nil
+}
+
+// getYarnWorkspacePatterns returns the package.json "workspaces" patterns which may be
+// an array or an object with a "packages" array.
+func getYarnWorkspacePatterns(workspaces any) []string {
+	if obj, isObj := workspaces.(map[string]any); isObj {
+		workspaces = obj["packages"]
+	}
+
+	arr, isArr := workspaces.([]any)
+	if !isArr {
+		return nil
+	}
+
+	patterns := make([]string, 0, len(arr))
+	for _, p := range arr {
+		if s, isStr := p.(string); isStr {
+			patterns = append(patterns, s)
+		}
+	}
+	return patterns
+}
@@ -1,12 +1,14 @@
 package gazelle
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path"
 	"strings"
 
 	common "github.com/aspect-build/aspect-gazelle/common"
+	"github.com/aspect-build/aspect-gazelle/common/cache"
 	BazelLog "github.com/aspect-build/aspect-gazelle/common/logger"
 	ruleUtils "github.com/aspect-build/aspect-gazelle/common/rule"
 	node "github.com/aspect-build/aspect-gazelle/language/js/node"
@@ -34,6 +36,10 @@ const (
 
 type ResolutionType = int
 
+// The ImportSpec language of the primary project of a tsconfig, distinct from
+// the ts_config target providing the tsconfig file itself.
+const tsconfigReferenceLang = LanguageName + ":tsconfig"
+
 // Name returns the name of the language. This is the prefix of the kinds of
 // rules generated. E.g. ts_project
 func (*typeScriptLang) Name() string { return LanguageName }
@@ -44,8 +50,8 @@ func (ts *typeScriptLang) Imports(c *config.Config, r *rule.Rule, f *rule.File)
 	BazelLog.Tracef("Imports(%s): //%s:%s", LanguageName, f.Pkg, r.Name())
 
 	switch r.Kind() {
-	case TsProtoLibraryKind:
-		return ts.protoLibraryImports(r, f)
+	case TsProtoLibraryKind, JsGrpcWebLibraryKind:
+		return ts.protoLibraryImports(c, r, f)
 	case TsConfigKind:
 		return ts.tsconfigImports(r, f)
 	case TsProjectKind:
@@ -60,7 +66,9 @@ func (ts *typeScriptLang) Imports(c *config.Config, r *rule.Rule, f *rule.File)
 
 // TypeScript-importable ImportSpecs from a set of source files.
 func (ts *typeScriptLang) sourceFileImports(c *config.Config, r *rule.Rule, f *rule.File) []resolve.ImportSpec {
-	var srcs []string
+	cfg := c.Exts[LanguageName].(*JsGazelleConfig)
+
+	var srcs, assets []string
 
 	infoAttr := r.PrivateAttr("ts_project_info")
 	if infoAttr != nil && infoAttr.(*TsProjectInfo).sources != nil {
@@ -71,6 +79,11 @@ func (ts *typeScriptLang) sourceFileImports(c *config.Config, r *rule.Rule, f *r
 		for it := srcsSet.Iterator(); it.Next(); {
 			srcs = append(srcs, it.Value().(string))
 		}
+
+		assetsSet := infoAttr.(*TsProjectInfo).assets
+		for it := assetsSet.Iterator(); it.Next(); {
+			assets = append(assets, it.Value().(string))
+		}
 	} else {
 		BazelLog.Debugf("Imports(%s): //%s:%s (non-generated %s)", LanguageName, f.Pkg, r.Name(), r.Kind())
 
@@ -86,6 +99,20 @@ func (ts *typeScriptLang) sourceFileImports(c *config.Config, r *rule.Rule, f *r
 		}
 
 		srcs = expandedSrcs
+
+		// The ts_project(assets) or assets within the srcs of other rule kinds
+		if assetsAttr := r.Attr("assets"); assetsAttr != nil {
+			expandedAssets, err := ruleUtils.ExpandSrcs(sourceFiles, assetsAttr)
+			if err != nil {
+				BazelLog.Debugf("Failed to expand assets of %s:%s - %v", f.Pkg, r.Name(), err)
+			}
+			assets = expandedAssets
+		}
+		for _, src := range srcs {
+			if cfg.IsAssetFile(src) {
+				assets = append(assets, src)
+			}
+		}
 	}
 
 	_, tsconfig := ts.tsconfig.FindConfig(f.Pkg)
@@ -121,6 +148,22 @@ func (ts *typeScriptLang) sourceFileImports(c *config.Config, r *rule.Rule, f *r
 		}
 	}
 
+	// Assets importable by their path such as stylesheets and images.
+	for _, asset := range assets {
+		provides = append(provides, resolve.ImportSpec{
+			Lang: LanguageName,
+			Imp:  path.Join(f.Pkg, asset),
+		})
+	}
+
+	// The primary project of a tsconfig referenced by other tsconfig "references".
+	if infoAttr != nil && infoAttr.(*TsProjectInfo).tsconfig != "" {
+		provides = append(provides, resolve.ImportSpec{
+			Lang: tsconfigReferenceLang,
+			Imp:  infoAttr.(*TsProjectInfo).tsconfig,
+		})
+	}
+
 	if len(provides) == 0 {
 		return nil
 	}
@@ -141,8 +184,8 @@ func (ts *typeScriptLang) tsconfigImports(r *rule.Rule, f *rule.File) []resolve.
 	}
 }
 
-// TypeScript-importable ImportSpecs from a TsProtoLibrary rule.
-func (ts *typeScriptLang) protoLibraryImports(r *rule.Rule, f *rule.File) []resolve.ImportSpec {
+// TypeScript-importable ImportSpecs from a TsProtoLibrary or JsGrpcWebLibrary rule.
+func (ts *typeScriptLang) protoLibraryImports(c *config.Config, r *rule.Rule, f *rule.File) []resolve.ImportSpec {
 	protoSrcsAttr := r.PrivateAttr("proto_library_srcs")
 
 	// The rule may not have been generated by this gazelle plugin
@@ -159,6 +202,16 @@ func (ts *typeScriptLang) protoLibraryImports(r *rule.Rule, f *rule.File) []reso
 		srcPath := path.Join(f.Pkg, src)
 		srcBase := strings.TrimSuffix(srcPath, ".proto")
 
+		// grpc-web services, and the google-protobuf messages unless generated by a ts_proto_library
+		if r.Kind() == JsGrpcWebLibraryKind {
+			dtsOutputs = append(dtsOutputs, srcBase+"_grpc_web_pb")
+
+			if cfg := c.Exts[LanguageName].(*JsGazelleConfig); !cfg.HasProtoFlavor(ProtoFlavorEs) && !cfg.HasProtoFlavor(ProtoFlavorConnect) {
+				dtsOutputs = append(dtsOutputs, srcBase+"_pb")
+			}
+			continue
+		}
+
 		// Messages: https://github.com/aspect-build/rules_ts/blob/v3.4.0/ts/private/ts_proto_library.bzl#L71
 		dtsOutputs = append(dtsOutputs, srcBase+"_pb")
 
@@ -299,11 +352,12 @@ func (ts *typeScriptLang) Resolve(
 
 	// TsProject imports are resolved as deps
 	switch r.Kind() {
-	case TsProjectKind, JsLibraryKind, JsTestKind, TsConfigKind, TsProtoLibraryKind:
+	case TsProjectKind, JsLibraryKind, JsTestKind, TsConfigKind, TsProtoLibraryKind, JsGrpcWebLibraryKind:
 		deps := common.NewLabelSet(from)
 
 		// Support this target representing a project or a package
 		var imports *treeset.Set
+		var references []string
 		if packageInfo, isPackageInfo := importData.(*TsPackageInfo); isPackageInfo {
 			imports = packageInfo.imports
 
@@ -312,17 +366,22 @@ func (ts *typeScriptLang) Resolve(
 			}
 		} else if projectInfo, isProjectInfo := importData.(*TsProjectInfo); isProjectInfo {
 			imports = projectInfo.imports
+			references = projectInfo.references
 		} else {
 			BazelLog.Infof("%s //%s:%s with no/unknown package info", r.Kind(), from.Pkg, r.Name())
 			break
 		}
 
+		ts.addImportGraphNode(c, from)
+
 		err := ts.resolveImports(c, ix, deps, imports, from)
 		if err != nil {
 			common.ImportErrorf(c, "Resolution Error: %v", err)
 			return
 		}
 
+		ts.resolveTsConfigReferences(c, ix, deps, references, from)
+
 		if r.Kind() == TsProjectKind {
 			ts.addTsLib(c, ix, deps, from)
 		}
@@ -330,6 +389,23 @@ func (ts *typeScriptLang) Resolve(
 		if !deps.Empty() {
 			r.SetAttr("deps", deps)
 		}
+	case JsBinaryKind:
+		projectInfo, isProjectInfo := importData.(*TsProjectInfo)
+		if !isProjectInfo {
+			BazelLog.Infof("%s //%s:%s with no/unknown project info", r.Kind(), from.Pkg, r.Name())
+			break
+		}
+
+		deps := common.NewLabelSet(from)
+		err := ts.resolveImports(c, ix, deps, projectInfo.imports, from)
+		if err != nil {
+			common.ImportErrorf(c, "Resolution Error: %v", err)
+			return
+		}
+
+		if !deps.Empty() {
+			r.SetAttr("data", deps)
+		}
 	case NpmPackageKind:
 		packageInfo, isPackageInfo := importData.(*TsPackageInfo)
 		if !isPackageInfo {
@@ -346,6 +422,8 @@ func (ts *typeScriptLang) Resolve(
 			return
 		}
 
+		ts.validateNpmPackageFiles(c, ix, from, packageInfo)
+
 		for dep := range deps.Labels() {
 			srcs = append(srcs, dep)
 		}
@@ -357,6 +435,34 @@ func (ts *typeScriptLang) Resolve(
 		if len(srcs) > 0 {
 			r.SetAttr("srcs", srcs)
 		}
+	case JestTestKind, VitestTestKind, MochaTestKind:
+		packageInfo, isPackageInfo := importData.(*TsPackageInfo)
+		if !isPackageInfo {
+			BazelLog.Infof("%s //%s:%s with no/unknown package info", r.Kind(), from.Pkg, r.Name())
+			break
+		}
+
+		// The tests, setup files and snapshots
+		data := packageInfo.sources.Values()
+
+		deps := common.NewLabelSet(from)
+		if packageInfo.source != nil {
+			deps.Add(packageInfo.source)
+		}
+
+		err := ts.resolveImports(c, ix, deps, packageInfo.imports, from)
+		if err != nil {
+			common.ImportErrorf(c, "Resolution Error: %v", err)
+			return
+		}
+
+		for dep := range deps.Labels() {
+			data = append(data, dep)
+		}
+
+		if len(data) > 0 {
+			r.SetAttr("data", data)
+		}
 	}
 }
 func (ts *typeScriptLang) addTsLib(
@@ -383,6 +489,8 @@ func (ts *typeScriptLang) resolveImports(
 	cfg := c.Exts[LanguageName].(*JsGazelleConfig)
 
 	resolutionErrors := []error{}
+	urlErrors := []error{}
+	npmErrors := []error{}
 
 	it := imports.Iterator()
 	for it.Next() {
@@ -391,15 +499,28 @@ func (ts *typeScriptLang) resolveImports(
 		// Overrides override all
 		if override, ok := resolve.FindRuleWithOverride(c, imp.ImportSpec, LanguageName); ok {
 			deps.Add(&override)
+			ts.addImportGraphEdge(from, &override, imp)
 			continue
 		}
 
 		// JS Overrides (js_resolve) override all
 		if res := cfg.GetResolution(imp.Imp); res != nil {
 			deps.Add(res)
+			ts.addImportGraphEdge(from, res, imp)
 			continue
 		}
 
+		// Specifiers mapped by an import map or Deno "npm:" specifiers
+		if mappedImp := ts.mapImportSpecifier(cfg, imp); mappedImp.Imp != imp.Imp {
+			imp = mappedImp
+
+			if res := cfg.GetResolution(imp.Imp); res != nil {
+				deps.Add(res)
+				ts.addImportGraphEdge(from, res, imp)
+				continue
+			}
+		}
+
 		resolutionType, dep, err := ts.resolveImport(c, ix, from, imp)
 		if err != nil {
 			return err
@@ -408,16 +529,53 @@ func (ts *typeScriptLang) resolveImports(
 		types := ts.resolveImportTypes(c, ix, resolutionType, from, imp)
 		for _, typesDep := range types {
 			deps.Add(typesDep)
+			ts.addImportGraphEdge(from, typesDep, imp)
+		}
+
+		// Phantom npm dependencies and the usage of declared npm dependencies
+		if err := ts.validateNpmDependency(cfg, from, imp, resolutionType, dep, types); err != nil {
+			npmErrors = append(npmErrors, err)
 		}
 
 		if dep != nil && (!imp.TypesOnly || len(types) == 0) {
-			deps.Add(dep)
+			// Named imports of barrel files depend on the targets defining the names
+			if narrowed := ts.narrowBarrelImport(c, ix, from, imp, dep); narrowed != nil {
+				for _, narrowedDep := range narrowed {
+					deps.Add(narrowedDep)
+					ts.addImportGraphEdge(from, narrowedDep, imp)
+				}
+			} else {
+				deps.Add(dep)
+				ts.addImportGraphEdge(from, dep, imp)
+			}
 		}
 
 		// Neither the import or a type definition was found.
 		if resolutionType == Resolution_NotFound && len(types) == 0 {
 			if imp.Optional {
 				BazelLog.Infof("Optional import %q for target %v not found", imp.ImportPath, from)
+			} else if isUrlImport(imp.Imp) {
+				// URL imports with no declared module can only be resolved using js_resolve
+				if cfg.ValidateUrlImports() != ValidationOff {
+					BazelLog.Debugf("URL import %q for target %v not resolved", imp.ImportPath, from)
+
+					notResolved := fmt.Errorf(
+						"Import %[1]q from %[2]q is a URL import with no known dependency. Possible solutions:\n"+
+							"\t1. Instruct Gazelle to resolve to a known dependency using a directive:\n"+
+							"\t\t# aspect:js_resolve url-glob label\n"+
+							"\t2. Ignore the dependency using the '# aspect:%[3]s %[1]s' directive.\n"+
+							"\t3. Disable Gazelle URL import validation using '# aspect:%[4]s off'",
+						imp.Imp, imp.SourcePath, Directive_IgnoreImports, Directive_ValidateUrlImports,
+					)
+					urlErrors = append(urlErrors, notResolved)
+				}
+			} else if cfg.platform != PlatformNeutral && node.IsNodeImport(imp.Imp) {
+				// Node builtins not available on the platform or node version
+				if cfg.ValidateImportStatements() != ValidationOff {
+					BazelLog.Debugf("node builtin import %q for target %v not available", imp.ImportPath, from)
+
+					resolutionErrors = append(resolutionErrors, nodeBuiltinUnavailableError(cfg, imp))
+				}
 			} else if cfg.ValidateImportStatements() != ValidationOff {
 				BazelLog.Debugf("import %q for target %v not found", imp.ImportPath, from)
 
@@ -441,21 +599,100 @@ func (ts *typeScriptLang) resolveImports(
 	}
 
 	// Log any resolution errorsResolution errors and error out.
-	if len(resolutionErrors) > 0 {
-		joinedErrs := ""
-		for _, err := range resolutionErrors {
-			joinedErrs = fmt.Sprintf("%s\n\n%s", joinedErrs, err)
-		}
+	reportResolutionErrors(c, cfg.ValidateImportStatements(), from, resolutionErrors)
+	reportResolutionErrors(c, cfg.ValidateUrlImports(), from, urlErrors)
+	reportResolutionErrors(c, cfg.ValidateNpmDependencies(), from, npmErrors)
+
+	return nil
+}
+
+func nodeBuiltinUnavailableError(cfg *JsGazelleConfig, imp ImportStatement) error {
+	if cfg.platform == PlatformBrowser {
+		return fmt.Errorf(
+			"Import %[1]q from %[2]q is a node builtin module not available on the %[3]q platform. Possible solutions:\n"+
+				"\t1. Instruct Gazelle to resolve to a browser compatible package such as a polyfill using a directive:\n"+
+				"\t\t# aspect:js_resolve %[1]s label\n"+
+				"\t2. Change the platform using the '# aspect:%[4]s node' directive.\n"+
+				"\t3. Ignore the dependency using the '# aspect:%[5]s %[1]s' directive.\n"+
+				"\t4. Disable Gazelle resolution validation using '# aspect:%[6]s off'",
+			imp.ImportPath, imp.SourcePath, cfg.platform, Directive_Platform, Directive_IgnoreImports, Directive_ValidateImportStatements,
+		)
+	}
+
+	return fmt.Errorf(
+		"Import %[1]q from %[2]q is not a builtin module of node %[3]s. Possible solutions:\n"+
+			"\t1. Change the node version using the '# aspect:%[4]s version' directive.\n"+
+			"\t2. Ignore the dependency using the '# aspect:%[5]s %[1]s' directive.\n"+
+			"\t3. Disable Gazelle resolution validation using '# aspect:%[6]s off'",
+		imp.ImportPath, imp.SourcePath, cfg.nodeVersion, Directive_NodeVersion, Directive_IgnoreImports, Directive_ValidateImportStatements,
+	)
+}
+
+func reportResolutionErrors(c *config.Config, mode ValidationMode, from label.Label, resolutionErrors []error) {
+	if len(resolutionErrors) == 0 {
+		return
+	}
+
+	joinedErrs := ""
+	for _, err := range resolutionErrors {
+		joinedErrs = fmt.Sprintf("%s\n\n%s", joinedErrs, err)
+	}
 
-		switch cfg.ValidateImportStatements() {
-		case ValidationError:
-			common.ImportErrorf(c, "Failed to validate dependencies for target %q:%v\n", from, joinedErrs)
-		case ValidationWarn:
-			fmt.Fprintf(os.Stderr, "Warning: Failed to validate dependencies for target %q:%v\n", from, joinedErrs)
+	switch mode {
+	case ValidationError:
+		common.ImportErrorf(c, "Failed to validate dependencies for target %q:%v\n", from, joinedErrs)
+	case ValidationWarn:
+		fmt.Fprintf(os.Stderr, "Warning: Failed to validate dependencies for target %q:%v\n", from, joinedErrs)
+	}
+}
+
+// Map an import specifier using the configured import map and convert Deno "npm:"
+// specifiers to package specifiers.
+func (ts *typeScriptLang) mapImportSpecifier(cfg *JsGazelleConfig, imp ImportStatement) ImportStatement {
+	// Relative imports are not mapped
+	if imp.ImportPath == "" || imp.ImportPath[0] == '.' {
+		return imp
+	}
+
+	if cfg.importMap != nil {
+		if mapped, found := cfg.importMap.Resolve(path.Dir(cfg.importMapFile), imp.SourcePath, imp.ImportPath); found {
+			BazelLog.Tracef("import %q mapped to %q by %q", imp.ImportPath, mapped, cfg.importMapFile)
+			imp.Imp = mapped
+			return imp
 		}
 	}
 
-	return nil
+	if strings.HasPrefix(imp.ImportPath, "npm:") {
+		imp.Imp = toNpmSpecifier(imp.ImportPath)
+	}
+
+	return imp
+}
+
+// Resolve the projects referenced via tsconfig "references" to the primary project
+// of the referenced tsconfig.
+func (ts *typeScriptLang) resolveTsConfigReferences(
+	c *config.Config,
+	ix *resolve.RuleIndex,
+	deps *common.LabelSet,
+	references []string,
+	from label.Label,
+) {
+	for _, reference := range references {
+		matches := ix.FindRulesByImportWithConfig(c, resolve.ImportSpec{Lang: tsconfigReferenceLang, Imp: reference}, LanguageName)
+
+		found := false
+		for _, match := range matches {
+			if !match.IsSelfImport(from) {
+				deps.Add(&match.Label)
+				found = true
+			}
+		}
+
+		if !found {
+			BazelLog.Infof("Referenced tsconfig %q project for target %v not found", reference, from)
+		}
+	}
 }
 
 func (ts *typeScriptLang) resolveImport(
@@ -466,6 +703,16 @@ func (ts *typeScriptLang) resolveImport(
 ) (ResolutionType, *label.Label, error) {
 	imp := impStm.ImportSpec
 
+	// References to local workspace packages via the package.json exports, resolved to
+	// the targets of the package sources before the package link within node_modules
+	if pkg, subFile := node.ParseImportPath(imp.Imp); pkg != "" {
+		for _, p := range ts.expandPackageExports(c, from, pkg, subFile) {
+			if resolution, match, err := ts.resolveExpandedImport(c, ix, from, impStm, p); resolution != Resolution_NotFound {
+				return resolution, match, err
+			}
+		}
+	}
+
 	// Gazelle rule index
 	if resolution, match, err := ts.resolveExplicitImportFromIndex(c, ix, from, impStm); resolution != Resolution_NotFound {
 		return resolution, match, err
@@ -476,32 +723,160 @@ func (ts *typeScriptLang) resolveImport(
 		return Resolution_Label, importLabel, nil
 	}
 
+	// References to the source of a transpiled file such as `new URL("./worker.ts", import.meta.url)`
+	if isTranspiledSourceFileType(imp.Imp) {
+		if resolution, match, err := ts.resolveExpandedImport(c, ix, from, impStm, toJsFile(imp.Imp)); resolution != Resolution_NotFound {
+			return resolution, match, err
+		}
+	}
+
+	// References via package.json subpath imports
+	if strings.HasPrefix(impStm.ImportPath, "#") {
+		for _, p := range ts.expandPackageImports(c, impStm.SourcePath, impStm.ImportPath) {
+			if resolution, match, err := ts.resolveExpandedImport(c, ix, from, impStm, p); resolution != Resolution_NotFound {
+				return resolution, match, err
+			}
+		}
+	}
+
 	// References via tsconfig mappings (paths, baseUrl, rootDirs etc.)
 	if tsconfigPaths := ts.tsconfig.ExpandPaths(impStm.SourcePath, impStm.ImportPath); len(tsconfigPaths) > 0 {
 		for _, p := range tsconfigPaths {
-			pImp := ImportStatement{
-				ImportSpec: resolve.ImportSpec{
-					Lang: impStm.ImportSpec.Lang,
-					Imp:  toImportSpecPath(impStm.SourcePath, p),
-				},
-				SourcePath: impStm.SourcePath,
-				ImportPath: impStm.ImportPath,
-				Optional:   impStm.Optional,
-			}
-			if resolution, match, err := ts.resolveExplicitImportFromIndex(c, ix, from, pImp); resolution != Resolution_NotFound {
+			if resolution, match, err := ts.resolveExpandedImport(c, ix, from, impStm, toImportSpecPath(impStm.SourcePath, p)); resolution != Resolution_NotFound {
 				return resolution, match, err
 			}
 		}
 	}
 
-	// Native node imports
-	if node.IsNodeImport(imp.Imp) {
+	// Native node imports of the node platform and version
+	if cfg := c.Exts[LanguageName].(*JsGazelleConfig); cfg.IsNodeBuiltinImport(imp.Imp) {
 		return Resolution_NativeNode, nil, nil
 	}
 
 	return Resolution_NotFound, nil, nil
 }
 
+// Resolve an import via an alternate import path such as a tsconfig or package.json mapping.
+func (ts *typeScriptLang) resolveExpandedImport(c *config.Config, ix *resolve.RuleIndex, from label.Label, impStm ImportStatement, imp string) (ResolutionType, *label.Label, error) {
+	return ts.resolveExplicitImportFromIndex(c, ix, from, ImportStatement{
+		ImportSpec: resolve.ImportSpec{
+			Lang: impStm.ImportSpec.Lang,
+			Imp:  imp,
+		},
+		SourcePath: impStm.SourcePath,
+		ImportPath: impStm.ImportPath,
+		Optional:   impStm.Optional,
+	})
+}
+
+// Expand a package.json subpath import such as "#utils/a" using the package.json
+// closest to the importing file.
+//
+// Returns workspace relative paths for local targets and package specifiers for others.
+func (ts *typeScriptLang) expandPackageImports(c *config.Config, from, specifier string) []string {
+	pkgDir := path.Dir(from)
+	for {
+		if pkgDir == "." {
+			pkgDir = ""
+		}
+		if ts.packageJsonDirs[pkgDir] {
+			break
+		}
+		if pkgDir == "" {
+			return nil
+		}
+		pkgDir = path.Dir(pkgDir)
+	}
+
+	packageJsonPath := path.Join(pkgDir, NpmPackageFilename)
+
+	packageImports, _, err := cache.Get(c).LoadOrStoreFile(c.RepoRoot, packageJsonPath, "parsePackageJsonSubpathImports", func(path string, content []byte) (any, error) {
+		return node.ParsePackageJsonSubpathImports(bytes.NewReader(content))
+	})
+	if err != nil {
+		BazelLog.Warnf("Failed to parse %q imports: %v", packageJsonPath, err)
+		return nil
+	}
+
+	cfg := c.Exts[LanguageName].(*JsGazelleConfig)
+
+	targets := packageImports.(node.PackageImports).Expand(specifier, cfg.PackageConditions())
+	for i, t := range targets {
+		if strings.HasPrefix(t, "./") || strings.HasPrefix(t, "../") {
+			targets[i] = path.Join(pkgDir, t)
+		} else {
+			targets[i] = path.Clean(t)
+		}
+	}
+
+	BazelLog.Tracef("package.json %q imports %q expanded to: %v", packageJsonPath, specifier, targets)
+
+	return targets
+}
+
+// Expand an import of a workspace package such as "@myorg/lib/feature/x" to the
+// possible source files using the package.json "exports" and "typesVersions".
+//
+// Returns workspace relative paths.
+func (ts *typeScriptLang) expandPackageExports(c *config.Config, from label.Label, pkg, subFile string) []string {
+	pkgDir, found := ts.findWorkspacePackageDir(from.Pkg, pkg)
+	if !found {
+		return nil
+	}
+
+	entryPoints, err := loadPackageEntryPoints(c, pkgDir)
+	if err != nil {
+		BazelLog.Warnf("Failed to parse %q exports: %v", path.Join(pkgDir, NpmPackageFilename), err)
+		return nil
+	}
+
+	// Packages with no "exports" or "typesVersions" are resolved to the package itself
+	if entryPoints.Exports == nil && len(entryPoints.TypesVersions) == 0 {
+		return nil
+	}
+
+	subpath := "."
+	if subFile != "" {
+		subpath = "./" + subFile
+	}
+
+	cfg := c.Exts[LanguageName].(*JsGazelleConfig)
+
+	files := entryPoints.Resolve(subpath, cfg.PackageConditions())
+	for i, f := range files {
+		files[i] = path.Join(pkgDir, f)
+	}
+
+	BazelLog.Tracef("package %q (%q) subpath %q expanded to: %v", pkg, pkgDir, subpath, files)
+
+	return files
+}
+
+// Find the directory of a workspace package declared as a local reference, such as
+// a `workspace:` dependency, of the importing pnpm project or its parent projects.
+//
+// Packages not declared by the importing project are never resolved to a package.json
+// of the same name elsewhere in the repository.
+func (ts *typeScriptLang) findWorkspacePackageDir(from, pkg string) (string, bool) {
+	for p := ts.pnpmProjects.GetProject(from); p != nil; p = p.Parent() {
+		if dir, found := p.GetLocalReference(pkg); found {
+			return dir, ts.packageJsonDirs[dir]
+		}
+	}
+
+	return "", false
+}
+
+func loadPackageEntryPoints(c *config.Config, pkgDir string) (node.PackageEntryPoints, error) {
+	entryPoints, _, err := cache.Get(c).LoadOrStoreFile(c.RepoRoot, path.Join(pkgDir, NpmPackageFilename), "parsePackageJsonEntryPoints", func(path string, content []byte) (any, error) {
+		return node.ParsePackageJsonEntryPoints(bytes.NewReader(content))
+	})
+	if err != nil {
+		return node.PackageEntryPoints{}, err
+	}
+	return entryPoints.(node.PackageEntryPoints), nil
+}
+
 func (ts *typeScriptLang) resolveExplicitImportFromIndex(
 	c *config.Config,
 	ix *resolve.RuleIndex,
@@ -595,6 +970,11 @@ func (ts *typeScriptLang) resolveImportTypes(c *config.Config, ix *resolve.RuleI
 		if typeModules := ts.moduleTypes[imp.Imp]; typeModules != nil {
 			return typeModules
 		}
+
+		// Wildcard module definitions such as `declare module "*.svg"` matching the import
+		if pattern := ts.findModuleDeclarationPattern(imp.ImportPath); pattern != "" {
+			return ts.moduleTypes[pattern]
+		}
 	}
 
 	// No types found
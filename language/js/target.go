@@ -2,6 +2,7 @@ package gazelle
 
 import (
 	"path"
+	"slices"
 	"strings"
 
 	"github.com/bazelbuild/bazel-gazelle/label"
@@ -27,6 +28,10 @@ type ImportStatement struct {
 	// If the import is explicitly for types, in which case prefer @types package
 	// dependencies when types are shipped separately
 	TypesOnly bool
+
+	// The names imported from the module such as "default" or the names within
+	// `import { a, b }`, nil if the whole module may be used
+	Names []string
 }
 
 // Npm link-all rule import data
@@ -40,6 +45,9 @@ type TsPackageInfo struct {
 	TsProjectInfo
 
 	source *label.Label
+
+	// The package.json "files" entries matching no file of the package
+	unmatchedFiles []string
 }
 
 func newTsPackageInfo(source *label.Label) *TsPackageInfo {
@@ -47,6 +55,7 @@ func newTsPackageInfo(source *label.Label) *TsPackageInfo {
 		TsProjectInfo: TsProjectInfo{
 			imports: treeset.NewWith(importStatementComparator),
 			sources: treeset.NewWithStringComparator(),
+			assets:  treeset.NewWithStringComparator(),
 		},
 		source: source,
 	}
@@ -57,17 +66,47 @@ type TsProjectInfo struct {
 	// `ImportStatement`s in ths project
 	imports *treeset.Set
 
+	// The `ImportStatement`s added by AddImport by import path, one per imported module
+	importsByPath map[string]ImportStatement
+
 	// The 'srcs' of this project
 	sources *treeset.Set
+
+	// The imported assets of this project such as stylesheets and images
+	assets *treeset.Set
+
+	// The tsconfig file if this is the primary project of a tsconfig
+	tsconfig string
+
+	// The tsconfig files of referenced projects (tsconfig "references")
+	references []string
 }
 
 func newTsProjectInfo() *TsProjectInfo {
 	return &TsProjectInfo{
 		imports: treeset.NewWith(importStatementComparator),
 		sources: treeset.NewWithStringComparator(),
+		assets:  treeset.NewWithStringComparator(),
 	}
 }
+
 func (i *TsProjectInfo) AddImport(impt ImportStatement) {
+	if i.importsByPath == nil {
+		i.importsByPath = make(map[string]ImportStatement)
+	}
+
+	// Merge the names imported by each statement of the same module
+	if existing, hasExisting := i.importsByPath[impt.Imp]; hasExisting && impt.Names != nil {
+		if existing.Names == nil {
+			impt.Names = nil
+		} else {
+			names := slices.Concat(existing.Names, impt.Names)
+			slices.Sort(names)
+			impt.Names = slices.Compact(names)
+		}
+	}
+
+	i.importsByPath[impt.Imp] = impt
 	i.imports.Add(impt)
 }
 
@@ -0,0 +1,268 @@
+package gazelle
+
+import (
+	"encoding/gob"
+	"path"
+	"regexp"
+	"strings"
+
+	common "github.com/aspect-build/aspect-gazelle/common"
+	"github.com/aspect-build/aspect-gazelle/common/cache"
+	BazelLog "github.com/aspect-build/aspect-gazelle/common/logger"
+	ruleUtils "github.com/aspect-build/aspect-gazelle/common/rule"
+	"github.com/aspect-build/aspect-gazelle/language/js/parser"
+	"github.com/bazelbuild/bazel-gazelle/config"
+	"github.com/bazelbuild/bazel-gazelle/label"
+	"github.com/bazelbuild/bazel-gazelle/language"
+	"github.com/bazelbuild/bazel-gazelle/resolve"
+	"github.com/bazelbuild/bazel-gazelle/rule"
+)
+
+// A test runner detected by its configuration file.
+type TestRunner string
+
+const (
+	TestRunnerJest   TestRunner = "jest"
+	TestRunnerVitest TestRunner = "vitest"
+	TestRunnerMocha  TestRunner = "mocha"
+)
+
+// The configuration files of each test runner, in order of precedence.
+var testRunnerConfigFiles = []struct {
+	runner TestRunner
+	files  []string
+}{
+	{TestRunnerJest, []string{"jest.config.js", "jest.config.ts", "jest.config.mjs", "jest.config.cjs", "jest.config.mts", "jest.config.cts", "jest.config.json"}},
+	{TestRunnerVitest, []string{"vitest.config.ts", "vitest.config.js", "vitest.config.mts", "vitest.config.mjs", "vitest.config.cts", "vitest.config.cjs"}},
+	{TestRunnerMocha, []string{".mocharc.js", ".mocharc.cjs", ".mocharc.mjs", ".mocharc.yaml", ".mocharc.yml", ".mocharc.jsonc", ".mocharc.json"}},
+}
+
+// The test runner configuration of a directory and its subdirectories.
+type testRunnerConfig struct {
+	runner TestRunner
+
+	// The workspace relative path of the configuration file
+	configFile string
+}
+
+func init() {
+	gob.Register(parser.TestRunnerConfig{})
+}
+
+// Find a test runner configuration file within the directory.
+func findTestRunnerConfig(rel string) *testRunnerConfig {
+	for _, r := range testRunnerConfigFiles {
+		for _, f := range r.files {
+			if common.WalkHasPath(rel, f) {
+				return &testRunnerConfig{
+					runner:     r.runner,
+					configFile: path.Join(rel, f),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// The rule kind running the tests of the test runner, or "" if unknown.
+func (r TestRunner) ruleKind() string {
+	switch r {
+	case TestRunnerJest:
+		return JestTestKind
+	case TestRunnerVitest:
+		return VitestTestKind
+	case TestRunnerMocha:
+		return MochaTestKind
+	}
+	return ""
+}
+
+// If the rule kind of the test runner has a load, either a standard rule or
+// a macro mapped using the gazelle map_kind directive.
+func (r TestRunner) hasRuleLoad(c *config.Config) bool {
+	if r == TestRunnerJest {
+		return true
+	}
+	ruleKind := r.ruleKind()
+	if ruleKind == "" {
+		return false
+	}
+	_, isMapped := c.KindMap[ruleKind]
+	return isMapped
+}
+
+// The name of the rule running the tests of a test target.
+func toTestRunnerTargetName(testTargetName string, runner TestRunner) string {
+	return testTargetName + "_" + string(runner)
+}
+
+func (trc *testRunnerConfig) load(c *config.Config) parser.TestRunnerConfig {
+	r, _, err := cache.Get(c).LoadOrStoreFile(c.RepoRoot, trc.configFile, "js.ParseTestRunnerConfig", func(filePath string, content []byte) (any, error) {
+		return parser.ParseTestRunnerConfig(filePath, content)
+	})
+	if err != nil {
+		BazelLog.Warnf("Failed to parse %s config %q: %v", trc.runner, trc.configFile, err)
+	}
+	if r == nil {
+		return parser.TestRunnerConfig{}
+	}
+	return r.(parser.TestRunnerConfig)
+}
+
+// Convert a path of a test runner config to a workspace path, or return false
+// if the path is a package.
+//
+// Paths may be relative to the config file directory or the jest "<rootDir>".
+func (trc *testRunnerConfig) toWorkspacePath(p string) (string, bool) {
+	if rest, isRootDir := strings.CutPrefix(p, "<rootDir>"); isRootDir {
+		return path.Join(path.Dir(trc.configFile), rest), true
+	}
+	if strings.HasPrefix(p, "./") || strings.HasPrefix(p, "../") {
+		return path.Join(path.Dir(trc.configFile), p), true
+	}
+	return "", false
+}
+
+// A compiled jest moduleNameMapper entry.
+type moduleNameMapping struct {
+	pattern *regexp.Regexp
+	target  string
+}
+
+// The jest moduleNameMapper aliases applied to imports of tests.
+//
+// See https://jestjs.io/docs/configuration#modulenamemapper-objectstring-string--arraystring
+func (trc *testRunnerConfig) moduleNameMapper(c *config.Config) []moduleNameMapping {
+	if trc.runner != TestRunnerJest {
+		return nil
+	}
+
+	mappings := []moduleNameMapping{}
+	for _, m := range trc.load(c).ModuleNameMapper {
+		re, err := regexp.Compile(m.Pattern)
+		if err != nil {
+			BazelLog.Warnf("Invalid moduleNameMapper pattern %q in %q: %v", m.Pattern, trc.configFile, err)
+			continue
+		}
+		mappings = append(mappings, moduleNameMapping{pattern: re, target: m.Target})
+	}
+	return mappings
+}
+
+// Apply the first moduleNameMapper alias matching the import.
+func (trc *testRunnerConfig) mapImport(mappings []moduleNameMapping, imp ImportStatement) ImportStatement {
+	for _, m := range mappings {
+		match := m.pattern.FindStringSubmatchIndex(imp.ImportPath)
+		if match == nil {
+			continue
+		}
+
+		target := string(m.pattern.ExpandString(nil, m.target, imp.ImportPath, match))
+		if workspacePath, isPath := trc.toWorkspacePath(target); isPath {
+			imp.Imp = workspacePath
+		} else {
+			imp.Imp = target
+		}
+
+		BazelLog.Tracef("%q import %q mapped to %q by %q", imp.SourcePath, imp.ImportPath, imp.Imp, trc.configFile)
+		break
+	}
+	return imp
+}
+
+// Add the rule running the tests of a test target with the configured test runner.
+func (ts *typeScriptLang) addTestRunnerRule(cfg *JsGazelleConfig, args language.GenerateArgs, testRule *rule.Rule, result *language.GenerateResult) {
+	trc := cfg.getTestRunner()
+
+	ruleKind := trc.runner.ruleKind()
+	if ruleKind == "" {
+		BazelLog.Warnf("Unknown test runner %q of config %q", trc.runner, trc.configFile)
+		return
+	}
+
+	// Remove rules of other runners such as when migrating from jest to vitest.
+	for _, r := range testRunnerConfigFiles {
+		if r.runner != trc.runner {
+			ruleUtils.RemoveRule(args, toTestRunnerTargetName(testRule.Name(), r.runner), testRunnerRuleKinds, result)
+		}
+	}
+
+	info := newTsPackageInfo(&label.Label{
+		Name:     testRule.Name(),
+		Repo:     args.Config.RepoName,
+		Pkg:      args.Rel,
+		Relative: true,
+	})
+
+	// Setup files run before the tests
+	for _, setupFile := range trc.load(args.Config).SetupFiles {
+		imp := setupFile
+		if workspacePath, isPath := trc.toWorkspacePath(setupFile); isPath {
+			imp = workspacePath
+		}
+
+		info.AddImport(ImportStatement{
+			ImportSpec: resolve.ImportSpec{
+				Lang: LanguageName,
+				Imp:  imp,
+			},
+			ImportPath: setupFile,
+			SourcePath: trc.configFile,
+		})
+	}
+
+	// Snapshots of the tests such as __snapshots__/a.test.ts.snap
+	packageFiles, err := common.GetSourceRegularFiles(args.Rel)
+	if err != nil {
+		BazelLog.Warnf("Failed to list files of %q: %v", args.Rel, err)
+	}
+	for _, f := range packageFiles {
+		if strings.HasPrefix(f, "__snapshots__/") || strings.Contains(f, "/__snapshots__/") {
+			info.sources.Add(f)
+		}
+	}
+
+	runnerRule := rule.NewRule(ruleKind, toTestRunnerTargetName(testRule.Name(), trc.runner))
+	runnerRule.SetPrivateAttr("ts_project_info", info)
+
+	configLabel := label.New("", path.Dir(trc.configFile), path.Base(trc.configFile))
+	runnerRule.SetAttr("config", configLabel.Rel("", args.Rel).BzlExpr())
+
+	// The jest_test(node_modules) of the npm packages available to jest
+	if trc.runner == TestRunnerJest {
+		if pnpmProject := ts.pnpmProjects.GetProject(args.Rel); pnpmProject != nil {
+			nodeModulesLabel := label.New("", pnpmProject.Pkg(), cfg.npmLinkAllTargetName)
+			runnerRule.SetAttr("node_modules", nodeModulesLabel.Rel("", args.Rel).BzlExpr())
+		}
+	}
+
+	result.Gen = append(result.Gen, runnerRule)
+	result.Imports = append(result.Imports, info)
+
+	BazelLog.Infof("add rule '%s' '%s:%s'", runnerRule.Kind(), args.Rel, runnerRule.Name())
+}
+
+// If the file has a test runner rule of a test target, such as a jest_test() running the
+// testonly library of the test target.
+func hasTestRunnerRule(args language.GenerateArgs, testTargetName string) bool {
+	for _, r := range testRunnerConfigFiles {
+		existing := ruleUtils.GetFileRuleByName(args, toTestRunnerTargetName(testTargetName, r.runner))
+		if existing == nil {
+			continue
+		}
+
+		for it := testRunnerRuleKinds.Iterator(); it.Next(); {
+			if ruleUtils.MapKind(args, it.Value().(string)) == existing.Kind() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Remove the test runner rules of a test target.
+func removeTestRunnerRules(args language.GenerateArgs, testTargetName string, result *language.GenerateResult) {
+	for _, r := range testRunnerConfigFiles {
+		ruleUtils.RemoveRule(args, toTestRunnerTargetName(testTargetName, r.runner), testRunnerRuleKinds, result)
+	}
+}
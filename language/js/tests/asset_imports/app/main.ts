@@ -0,0 +1,7 @@
+import './main.css';
+import logo from './logo.svg';
+import '../styles/index';
+import '../styles/theme.scss';
+import '../lib/unowned.css';
+
+console.log(logo);
@@ -0,0 +1,4 @@
+// Names defined within the barrel itself
+import { VERSION } from '../ui';
+
+export const version = VERSION;
@@ -0,0 +1,4 @@
+// Namespace imports may use any name of the barrel
+import * as ui from '../ui';
+
+ui.Button();
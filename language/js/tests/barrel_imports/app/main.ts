@@ -0,0 +1,4 @@
+// Depends on the barrel and the target defining Button
+import { Button } from '../ui';
+
+Button();
@@ -0,0 +1,6 @@
+import { Card, Icon, theme } from '../ui/index';
+import type { CardProps } from '../ui';
+
+export const props: CardProps = { title: theme.dark };
+Card(props);
+Icon();
@@ -0,0 +1,5 @@
+export interface CardProps {
+    title: string;
+}
+
+export const Card = (props: CardProps) => props.title;
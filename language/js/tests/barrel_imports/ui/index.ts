@@ -0,0 +1,6 @@
+export * from './button';
+export { Card, type CardProps } from './card';
+export { default as Icon } from './icon';
+export * as theme from './theme';
+
+export const VERSION = '1.0.0';
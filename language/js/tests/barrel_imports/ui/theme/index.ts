@@ -0,0 +1 @@
+export const dark = '#000';
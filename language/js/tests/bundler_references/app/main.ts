@@ -0,0 +1,3 @@
+export const worker = new Worker(new URL('./worker.ts', import.meta.url), { type: 'module' });
+export const wasm = new URL('../wasm/module.wasm', import.meta.url);
+export const pages = import.meta.glob(['../pages/*.ts', '!../pages/draft.ts']);
@@ -0,0 +1,4 @@
+declare const require: any;
+
+export const lib = require.resolve('../lib');
+export const icons = require.context('../icons', false, /\.svg$/);
@@ -0,0 +1 @@
+self.onmessage = () => {};
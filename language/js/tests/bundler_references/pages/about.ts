@@ -0,0 +1 @@
+export const about = 'about';
@@ -0,0 +1 @@
+export const draft = 'draft';
@@ -0,0 +1 @@
+export const home = 'home';
@@ -0,0 +1,5 @@
+import readme from './README.mdx';
+import notes from '../docs/notes.txt?raw';
+import config from 'virtual:config';
+
+export default [readme, notes, config];
@@ -0,0 +1,4 @@
+// The most specific pattern takes precedence
+import { register } from 'virtual:pwa/register';
+
+register();
@@ -0,0 +1,14 @@
+declare module '*.mdx' {
+    const content: string;
+    export default content;
+}
+
+declare module '*?raw' {
+    const content: string;
+    export default content;
+}
+
+declare module 'virtual:*' {
+    const value: unknown;
+    export default value;
+}
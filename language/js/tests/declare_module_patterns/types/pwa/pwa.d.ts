@@ -0,0 +1,3 @@
+declare module 'virtual:pwa/*' {
+    export function register(): void;
+}
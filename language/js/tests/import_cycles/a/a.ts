@@ -0,0 +1,4 @@
+import { b } from '../b/b';
+import { d } from '../d';
+
+export const a = () => b() + d;
@@ -0,0 +1 @@
+export type A = string;
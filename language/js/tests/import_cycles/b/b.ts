@@ -0,0 +1,3 @@
+import { c } from '../c/c';
+
+export const b = () => c();
@@ -0,0 +1,4 @@
+import { a } from '../a/a';
+import type { A } from '../a/types';
+
+export const c = (): A => a();
@@ -0,0 +1,3 @@
+import { join } from './util';
+
+console.log(join('a', 'b'));
@@ -0,0 +1 @@
+export const join = (...parts: string[]) => parts.join('/');
@@ -0,0 +1,8 @@
+import { join } from "@std/path";
+import chalk from "chalk";
+import debounce from "npm:lodash-es@4.17.21/debounce";
+import React from "react";
+import { serve } from "https://deno.land/std@0.200.0/http/server.ts";
+import { greet } from "~/greet.ts";
+
+serve(() => new Response(chalk.blue(greet(join("a", "b")))));
@@ -0,0 +1,2 @@
+import { store } from "app/store";
+console.log(store);
@@ -0,0 +1,3 @@
+export function greet(s: string) {
+    return `Hello ${s}`;
+}
@@ -0,0 +1,4 @@
+import { a } from '@aspect-test/a';
+import { greet } from './lib';
+
+console.log(greet(a));
@@ -0,0 +1,3 @@
+import { greet } from '../cli/lib';
+
+console.log(greet('c'));
@@ -0,0 +1,3 @@
+import * as fs from 'node:fs';
+
+fs.writeFileSync('b.txt', 'b');
@@ -0,0 +1,4 @@
+import { d } from '../lib/d';
+import { a } from '../lib/a';
+
+console.log(a(), d);
@@ -0,0 +1,4 @@
+import { a } from './a';
+import { d } from './d';
+
+a(d);
@@ -0,0 +1,3 @@
+import { b } from './b';
+
+export const c = (n: number) => (n > 0 ? b() : n);
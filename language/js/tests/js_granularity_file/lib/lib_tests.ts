@@ -0,0 +1 @@
+export const name = 'lib';
@@ -0,0 +1,11 @@
+import { readFile } from 'fs/promises';
+import { join } from 'node:path';
+import { test } from 'node:test';
+
+// Added in node 22.5
+import { DatabaseSync } from 'node:sqlite';
+
+test('read', async () => {
+    await readFile(join(__dirname, 'data.json'));
+    new DatabaseSync(':memory:');
+});
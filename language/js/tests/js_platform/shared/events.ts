@@ -0,0 +1,4 @@
+// Resolved as any other import, such as to the npm events package
+import { EventEmitter } from 'events';
+
+export const emitter = new EventEmitter();
@@ -0,0 +1,3 @@
+import { join } from 'path';
+
+export const url = join('/api', 'data');
@@ -0,0 +1,7 @@
+import { ChatService } from '../chat/chat_connect';
+import { GreetRequest } from '../greet/greet_pb';
+import { GreetServiceClient } from '../greet/greet_grpc_web_pb';
+import { PingRequest } from '../web/web_pb';
+import { WebServiceClient } from '../web/web_grpc_web_pb';
+
+export { ChatService, GreetRequest, GreetServiceClient, PingRequest, WebServiceClient };
@@ -0,0 +1,4 @@
+import $ from 'jquery';
+import { value } from 'lib-a';
+
+$(() => console.log(value));
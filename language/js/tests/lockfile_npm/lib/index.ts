@@ -0,0 +1 @@
+export const value = 123;
@@ -0,0 +1,7 @@
+import { createElement } from 'react';
+import { lib } from '@ws/lib';
+
+// Declared by the root project, not app
+import { chunk } from 'lodash';
+
+export const element = createElement('div', null, chunk([lib], 1));
@@ -0,0 +1,3 @@
+import { debounce } from 'lodash';
+
+export const debounced = debounce(() => {}, 100);
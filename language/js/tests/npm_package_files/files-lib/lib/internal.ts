@@ -0,0 +1 @@
+export const internal = 1;
@@ -0,0 +1,4 @@
+import { lib } from '@myorg/lib';
+import { x } from '@myorg/lib/feature/x';
+
+console.log(lib, x);
@@ -0,0 +1 @@
+export const x = 'x';
@@ -0,0 +1 @@
+export const y = 'y';
@@ -0,0 +1,3 @@
+import { lib } from '@myorg/lib';
+
+console.log(lib);
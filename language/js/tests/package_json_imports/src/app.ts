@@ -0,0 +1,5 @@
+import { format } from '#utils/format';
+import { config } from '#config';
+import { lib } from '#lib';
+
+console.log(format(config.name), lib);
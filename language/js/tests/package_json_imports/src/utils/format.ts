@@ -0,0 +1 @@
+export function format(s: string) { return `[${s}]`; }
@@ -0,0 +1,6 @@
+import { greet } from '@/greet';
+import './greet.css';
+
+test('greet', () => {
+    expect(greet('world')).toMatchSnapshot();
+});
@@ -0,0 +1,9 @@
+module.exports = {
+    testEnvironment: 'node',
+    setupFiles: ['<rootDir>/setup.ts'],
+    setupFilesAfterEnv: ['jest-extended/all'],
+    moduleNameMapper: {
+        '^@/(.*)$': '<rootDir>/src/$1',
+        '\\.css$': 'identity-obj-proxy',
+    },
+};
@@ -0,0 +1,3 @@
+import { lib } from './lib';
+
+test('lib', () => expect(lib).toBe(1));
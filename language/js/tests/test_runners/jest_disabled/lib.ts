@@ -0,0 +1 @@
+export const lib = 1;
@@ -0,0 +1,3 @@
+import { lib } from './lib';
+
+describe('lib', () => it('is one', () => lib === 1));
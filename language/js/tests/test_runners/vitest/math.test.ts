@@ -0,0 +1,4 @@
+import { expect, test } from 'vitest';
+import { add } from './math';
+
+test('add', () => expect(add(1, 2)).toBe(3));
@@ -0,0 +1 @@
+import { vi } from 'vitest';
@@ -0,0 +1 @@
+export const app = 1;
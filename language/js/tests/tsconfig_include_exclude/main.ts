@@ -0,0 +1,2 @@
+import { a } from './src/a';
+export const main = a;
@@ -0,0 +1 @@
+export const other = 4;
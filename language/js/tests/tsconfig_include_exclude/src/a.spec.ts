@@ -0,0 +1,2 @@
+import { a } from './a';
+test(a);
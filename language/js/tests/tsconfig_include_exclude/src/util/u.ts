@@ -0,0 +1 @@
+export const u = 2;
@@ -3,6 +3,7 @@ package typescript
 import (
 	"fmt"
 	"path"
+	"slices"
 	"strings"
 	"sync"
 
@@ -64,27 +65,96 @@ func (tc *TsWorkspace) GetTsConfigFile(rel string) *TsConfig {
 		return nil
 	}
 
+	filePath := path.Join(p.rel, p.fileName)
+
+	c, err := tc.loadTsConfig(p.root, filePath)
+	if err != nil {
+		fmt.Printf("Failed to parse tsconfig file %s: %v\n", filePath, err)
+		return nil
+	}
+
+	return c
+}
+
+// GetReferencedTsConfig returns the path and parsed tsconfig of a tsconfig "references" path.
+//
+// The parsed tsconfig is nil if the file does not exist or fails to parse.
+func (tc *TsWorkspace) GetReferencedTsConfig(root, reference string) (string, *TsConfig) {
+	filePath := tc.GetReferenceFile(reference)
+
+	c, err := tc.loadTsConfig(root, filePath)
+	if err != nil {
+		BazelLog.Debugf("Failed to load referenced tsconfig file %s: %v", filePath, err)
+		return filePath, nil
+	}
+
+	return filePath, c
+}
+
+// GetReferenceFile returns the tsconfig file of a tsconfig "references" path which may be a
+// tsconfig file or a directory containing a tsconfig file.
+func (tc *TsWorkspace) GetReferenceFile(reference string) string {
+	if strings.HasSuffix(reference, ".json") {
+		return reference
+	}
+
+	rel := reference
+	if rel == "." {
+		rel = ""
+	}
+	if p := tc.cm.configFiles[rel]; p != nil {
+		return path.Join(p.rel, p.fileName)
+	}
+
+	return path.Join(reference, "tsconfig.json")
+}
+
+// FindReferenceCycle returns the chain of tsconfig "references" leading from the tsconfig
+// file back to itself, or nil if there is no such cycle.
+func (tc *TsWorkspace) FindReferenceCycle(root, filePath string) []string {
+	visited := map[string]bool{filePath: true}
+
+	var visit func(chain []string) []string
+	visit = func(chain []string) []string {
+		c, _ := tc.loadTsConfig(root, chain[len(chain)-1])
+		if c == nil {
+			return nil
+		}
+
+		for _, reference := range c.References {
+			referenceFile := tc.GetReferenceFile(reference)
+			if referenceFile == filePath {
+				return append(slices.Clone(chain), referenceFile)
+			}
+
+			if !visited[referenceFile] {
+				visited[referenceFile] = true
+				if cycle := visit(append(slices.Clone(chain), referenceFile)); cycle != nil {
+					return cycle
+				}
+			}
+		}
+
+		return nil
+	}
+
+	return visit([]string{filePath})
+}
+
+func (tc *TsWorkspace) loadTsConfig(root, filePath string) (*TsConfig, error) {
 	// Lock the configs mutex
 	tc.cm.configsMutex.Lock()
 	defer tc.cm.configsMutex.Unlock()
 
-	filePath := path.Join(p.rel, p.fileName)
-
 	// Check for previously parsed
 	if c := tc.cm.configs[filePath]; c != nil {
 		if c == &InvalidTsconfig {
-			return nil
+			return nil, nil
 		}
-		return c
+		return c, nil
 	}
 
-	c, err := parseTsConfigJSONFile(tc.cm.configs, tc.tsConfigResolver, p.root, filePath)
-	if err != nil {
-		fmt.Printf("Failed to parse tsconfig file %s: %v\n", filePath, err)
-		return nil
-	}
-
-	return c
+	return parseTsConfigJSONFile(tc.cm.configs, tc.tsConfigResolver, root, filePath)
 }
 
 // A `TsConfigResolver` to resolve imports from *within* tsconfig files
@@ -99,9 +169,14 @@ func (tc *TsWorkspace) tsConfigResolver(dir, rel string) []string {
 	if p := tc.cm.pnpmProjects.GetProject(dir); p != nil {
 		pkg, subFile := node.ParseImportPath(rel)
 		if pkg != "" {
-			localRef, found := p.GetLocalReference(pkg)
-			if found {
-				possible = append(possible, path.Join(localRef, subFile))
+			// Local workspace packages
+			if localRef, found := p.GetLocalReference(pkg); found {
+				possible = append(possible, tsConfigPackagePaths(localRef, subFile)...)
+			}
+
+			// Packages linked into the node_modules of the project or a parent project
+			if pkgLabel := p.Get(pkg); pkgLabel != nil {
+				possible = append(possible, tsConfigPackagePaths(path.Join(pkgLabel.Pkg, pkgLabel.Name), subFile)...)
 			}
 		}
 	}
@@ -109,6 +184,21 @@ func (tc *TsWorkspace) tsConfigResolver(dir, rel string) []string {
 	return possible
 }
 
+// The potential tsconfig files of an "extends" of a package or package subpath.
+//
+// See https://www.typescriptlang.org/tsconfig/#extends
+func tsConfigPackagePaths(pkgDir, subFile string) []string {
+	if subFile == "" {
+		return []string{path.Join(pkgDir, "tsconfig.json")}
+	}
+
+	possible := []string{path.Join(pkgDir, subFile)}
+	if !strings.HasSuffix(subFile, ".json") {
+		possible = append(possible, path.Join(pkgDir, subFile+".json"), path.Join(pkgDir, subFile, "tsconfig.json"))
+	}
+	return possible
+}
+
 func (tc *TsWorkspace) FindConfig(dir string) (string, *TsConfig) {
 	for {
 		if dir == "." {
@@ -0,0 +1,135 @@
+package typescript
+
+import (
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+
+	pnpm "github.com/aspect-build/aspect-gazelle/language/js/pnpm"
+	"github.com/bazelbuild/bazel-gazelle/label"
+)
+
+func writeTsConfigs(t *testing.T, files map[string]string) (string, *TsWorkspace) {
+	return writeTsConfigsWithProjects(t, pnpm.NewPnpmProjectMap(), files)
+}
+
+func writeTsConfigsWithProjects(t *testing.T, pnpmProjects *pnpm.PnpmProjectMap, files map[string]string) (string, *TsWorkspace) {
+	t.Helper()
+
+	root := t.TempDir()
+	ws := NewTsWorkspace(pnpmProjects)
+
+	for f, content := range files {
+		if err := os.MkdirAll(path.Join(root, path.Dir(f)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path.Join(root, f), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if path.Base(f) == "tsconfig.json" {
+			ws.SetTsConfigFile(root, path.Dir(f), "tsconfig.json")
+		}
+	}
+
+	return root, ws
+}
+
+func TestTsConfigReferences(t *testing.T) {
+	t.Run("reference files", func(t *testing.T) {
+		_, ws := writeTsConfigs(t, map[string]string{
+			"a/tsconfig.json": `{}`,
+		})
+		ws.SetTsConfigFile("", "b", "tsconfig.build.json")
+
+		assertEqual(t, ws.GetReferenceFile("a"), "a/tsconfig.json", "directory reference")
+		assertEqual(t, ws.GetReferenceFile("b"), "b/tsconfig.build.json", "directory reference with custom tsconfig name")
+		assertEqual(t, ws.GetReferenceFile("c"), "c/tsconfig.json", "directory reference without tsconfig")
+		assertEqual(t, ws.GetReferenceFile("a/tsconfig.lib.json"), "a/tsconfig.lib.json", "file reference")
+	})
+
+	t.Run("referenced tsconfig", func(t *testing.T) {
+		root, ws := writeTsConfigs(t, map[string]string{
+			"a/tsconfig.json": `{"compilerOptions": {"composite": true}}`,
+			"b/tsconfig.json": `{"references": [{"path": "../a"}, {"path": "../c"}]}`,
+		})
+
+		b := ws.GetTsConfigFile("b")
+		if !slices.Equal(b.References, []string{"a", "c"}) {
+			t.Errorf("References: expected [a c], got %v", b.References)
+		}
+
+		aPath, a := ws.GetReferencedTsConfig(root, b.References[0])
+		assertEqual(t, aPath, "a/tsconfig.json", "referenced tsconfig path")
+		if a == nil || a.Composite == nil || !*a.Composite {
+			t.Errorf("GetReferencedTsConfig: expected composite tsconfig, got %v", a)
+		}
+
+		cPath, c := ws.GetReferencedTsConfig(root, b.References[1])
+		assertEqual(t, cPath, "c/tsconfig.json", "missing tsconfig path")
+		if c != nil {
+			t.Errorf("GetReferencedTsConfig: expected nil for missing tsconfig, got %v", c)
+		}
+	})
+
+	t.Run("reference cycles", func(t *testing.T) {
+		root, ws := writeTsConfigs(t, map[string]string{
+			"a/tsconfig.json": `{"references": [{"path": "../b"}]}`,
+			"b/tsconfig.json": `{"references": [{"path": "../c/tsconfig.json"}, {"path": "../d"}]}`,
+			"c/tsconfig.json": `{"references": [{"path": "../a"}]}`,
+			"d/tsconfig.json": `{"references": [{"path": "../d"}]}`,
+			"e/tsconfig.json": `{"references": [{"path": "../d"}]}`,
+		})
+
+		cycle := ws.FindReferenceCycle(root, "a/tsconfig.json")
+		expected := []string{"a/tsconfig.json", "b/tsconfig.json", "c/tsconfig.json", "a/tsconfig.json"}
+		if !slices.Equal(cycle, expected) {
+			t.Errorf("FindReferenceCycle(a): expected %v, got %v", expected, cycle)
+		}
+
+		if cycle := ws.FindReferenceCycle(root, "d/tsconfig.json"); len(cycle) != 2 {
+			t.Errorf("FindReferenceCycle(d): expected self reference, got %v", cycle)
+		}
+
+		// Referencing a cycle is not itself a cycle
+		if cycle := ws.FindReferenceCycle(root, "e/tsconfig.json"); cycle != nil {
+			t.Errorf("FindReferenceCycle(e): expected no cycle, got %v", cycle)
+		}
+	})
+}
+
+func TestTsConfigExtendsPackages(t *testing.T) {
+	pnpmProjects := pnpm.NewPnpmProjectMap()
+	project := pnpmProjects.NewWorkspace("pnpm-lock.yaml").AddProject("")
+	project.AddPackage("@tsconfig/node20", "20.1.4", &label.Label{Name: "node_modules/@tsconfig/node20"})
+	project.AddPackage("@tsconfig/strictest", "2.0.5", &label.Label{Name: "node_modules/@tsconfig/strictest"})
+	project.AddPackage("shared-config", "1.0.0", &label.Label{Name: "node_modules/shared-config"})
+
+	_, ws := writeTsConfigsWithProjects(t, pnpmProjects, map[string]string{
+		"node_modules/@tsconfig/node20/tsconfig.json":    `{"compilerOptions": {"importHelpers": true, "jsx": "preserve"}}`,
+		"node_modules/@tsconfig/strictest/tsconfig.json": `{"compilerOptions": {"jsx": "react"}}`,
+		"node_modules/shared-config/base.json":           `{"compilerOptions": {"allowJs": true}}`,
+		"app/tsconfig.json":                              `{"extends": ["@tsconfig/node20/tsconfig.json", "@tsconfig/strictest"]}`,
+		"lib/tsconfig.json":                              `{"extends": "shared-config/base"}`,
+		"other/tsconfig.json":                            `{"extends": "@tsconfig/unknown/tsconfig.json"}`,
+	})
+
+	app := ws.GetTsConfigFile("app")
+	if !app.ImportHelpers {
+		t.Errorf("should inherit importHelpers from the npm package tsconfig")
+	}
+	assertEqual(t, string(app.Jsx), "react", "should inherit jsx from the last npm package tsconfig")
+	assertEqual(t, strings.Join(app.Extends, ","), "@tsconfig/node20/tsconfig.json,@tsconfig/strictest", "should record the extended packages")
+
+	lib := ws.GetTsConfigFile("lib")
+	if lib.AllowJs == nil || !*lib.AllowJs {
+		t.Errorf("should inherit allowJs from the npm package subpath tsconfig")
+	}
+
+	other := ws.GetTsConfigFile("other")
+	if other == nil {
+		t.Fatalf("should parse tsconfig extending an unknown package")
+	}
+	assertEqual(t, strings.Join(other.Extends, ","), "@tsconfig/unknown/tsconfig.json", "should record the unknown package")
+}
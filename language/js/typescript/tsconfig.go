@@ -17,13 +17,17 @@
 package typescript
 
 import (
+	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 
+	common "github.com/aspect-build/aspect-gazelle/common"
 	BazelLog "github.com/aspect-build/aspect-gazelle/common/logger"
 	"github.com/msolo/jsonr"
 )
@@ -55,10 +59,32 @@ type tsReferenceJSON struct {
 	Path string `json:"path"`
 }
 
+// The tsconfig "extends" which may be a single config or an array of configs.
+// See https://www.typescriptlang.org/tsconfig/#extends
+type tsExtendsJSON []string
+
+func (e *tsExtendsJSON) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*e = tsExtendsJSON{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return fmt.Errorf("invalid tsconfig extends, expected string or array of strings: %s", data)
+	}
+	*e = multiple
+	return nil
+}
+
 type tsConfigJSON struct {
-	Extends         string                `json:"extends"`
+	Extends         tsExtendsJSON         `json:"extends"`
 	CompilerOptions tsCompilerOptionsJSON `json:"compilerOptions"`
 	References      *[]tsReferenceJSON    `json:"references"`
+	Files           *[]string             `json:"files"`
+	Include         *[]string             `json:"include"`
+	Exclude         *[]string             `json:"exclude"`
 }
 
 type TsConfigResolver = func(dir, conf string) []string
@@ -114,11 +140,21 @@ type TsConfig struct {
 	Jsx TsConfigJsxType
 
 	// References to other tsconfig or packages that must be resolved.
-	Types   []string
-	Extends string
+	Types []string
+
+	// The tsconfig "extends" in order of precedence, lowest first.
+	Extends []string
 
-	// TODO: drop references? Not supported by rules_ts?
+	// The project "references" paths relative to the root, either a directory
+	// containing a tsconfig or a tsconfig file.
+	// See https://www.typescriptlang.org/docs/handbook/project-references.html
 	References []string
+
+	// The files, include and exclude patterns relative to ConfigDir, nil if not set.
+	// See https://www.typescriptlang.org/tsconfig/#files
+	Files   []string
+	Include []string
+	Exclude []string
 }
 
 type TsConfigPaths struct {
@@ -165,6 +201,7 @@ func parseTsConfigJSONFile(parsed map[string]*TsConfig, resolver TsConfigResolve
 
 	tsconfigFile, err := os.OpenFile(path.Join(root, tsconfig), os.O_RDONLY, os.FileMode(os.O_RDONLY))
 	if err != nil {
+		delete(parsed, tsconfig)
 		return nil, err
 	}
 	defer tsconfigFile.Close()
@@ -184,23 +221,86 @@ func parseTsConfigJSON(parsed map[string]*TsConfig, resolver TsConfigResolver, r
 		return nil, err
 	}
 
-	var baseConfig *TsConfig
-	var extends string
-	if c.Extends != "" {
-		// Load the extended config if it can be resolved.
-		// Extending external config such as npm packages can not be loaded but should
-		// still be recorderded for computing dependencies.
-		extends = path.Clean(c.Extends)
+	return resolveTsConfigJSON(parsed, resolver, root, tsconfig, &c, nil)
+}
+
+// Parse a tsconfig file inheriting from a base config preceding the "extends" of the file,
+// such as an earlier entry of an "extends" array. The result is specific to the base so
+// is not cached.
+func parseTsConfigJSONFileWithBase(parsed map[string]*TsConfig, resolver TsConfigResolver, root, tsconfig string, base *TsConfig) (*TsConfig, error) {
+	existing, hasExisting := parsed[tsconfig]
 
-		for _, potential := range resolver(path.Dir(tsconfig), c.Extends) {
-			base, err := parseTsConfigJSONFile(parsed, resolver, root, potential)
+	// Existing pointing to `InvalidTsconfig` implies recursion
+	if existing == &InvalidTsconfig {
+		BazelLog.Warnf("Recursive tsconfig file extension: %q", tsconfig)
+		return nil, nil
+	}
 
-			if err != nil {
-				BazelLog.Warnf("Failed to load base tsconfig file %q from %q: %v", c.Extends, tsconfig, err)
-			} else if base != nil {
-				baseConfig = base
-				break
+	content, err := os.ReadFile(path.Join(root, tsconfig))
+	if err != nil {
+		return nil, err
+	}
+
+	var c tsConfigJSON
+	if err := jsonr.Unmarshal(content, &c); err != nil {
+		return nil, err
+	}
+
+	// Mark as invalid while resolving to prevent recursing into the same file
+	parsed[tsconfig] = &InvalidTsconfig
+	defer func() {
+		if hasExisting {
+			parsed[tsconfig] = existing
+		} else {
+			delete(parsed, tsconfig)
+		}
+	}()
+
+	return resolveTsConfigJSON(parsed, resolver, root, tsconfig, &c, base)
+}
+
+// Load a config extended by a tsconfig, optionally inheriting from a base config
+// of a preceding "extends" entry.
+func loadExtendedTsConfig(parsed map[string]*TsConfig, resolver TsConfigResolver, root, tsconfig, extends string, base *TsConfig) *TsConfig {
+	for _, potential := range resolver(path.Dir(tsconfig), extends) {
+		var extended *TsConfig
+		var err error
+		if base == nil {
+			extended, err = parseTsConfigJSONFile(parsed, resolver, root, potential)
+		} else {
+			extended, err = parseTsConfigJSONFileWithBase(parsed, resolver, root, potential, base)
+		}
+
+		if err != nil {
+			// Potential paths such as node_modules may not exist
+			if os.IsNotExist(err) && !isRelativePath(extends) {
+				BazelLog.Debugf("Base tsconfig file %q from %q not found at %q", extends, tsconfig, potential)
+			} else {
+				BazelLog.Warnf("Failed to load base tsconfig file %q from %q: %v", extends, tsconfig, err)
 			}
+		} else if extended != nil {
+			return extended
+		}
+	}
+
+	return nil
+}
+
+func resolveTsConfigJSON(parsed map[string]*TsConfig, resolver TsConfigResolver, root, tsconfig string, c *tsConfigJSON, base *TsConfig) (*TsConfig, error) {
+	// Load the extended configs if they can be resolved, each inheriting from the previous.
+	// Extending external config such as npm packages may not be loadable but should
+	// still be recorded for computing dependencies.
+	baseConfig := base
+	var extends []string
+	for _, e := range c.Extends {
+		if e == "" {
+			continue
+		}
+
+		extends = append(extends, path.Clean(e))
+
+		if extended := loadExtendedTsConfig(parsed, resolver, root, tsconfig, e, baseConfig); extended != nil {
+			baseConfig = extended
 		}
 	}
 
@@ -380,6 +480,24 @@ func parseTsConfigJSON(parsed map[string]*TsConfig, resolver TsConfigResolver, r
 		jsx = baseConfig.Jsx
 	}
 
+	// files, include and exclude are inherited but relative to the config that declared them
+	var files, include, exclude []string
+	if c.Files != nil {
+		files = cleanTsConfigPatterns(".", *c.Files)
+	} else if baseConfig != nil && baseConfig.Files != nil {
+		files = cleanTsConfigPatterns(baseConfigRel, baseConfig.Files)
+	}
+	if c.Include != nil {
+		include = cleanTsConfigPatterns(".", *c.Include)
+	} else if baseConfig != nil && baseConfig.Include != nil {
+		include = cleanTsConfigPatterns(baseConfigRel, baseConfig.Include)
+	}
+	if c.Exclude != nil {
+		exclude = cleanTsConfigPatterns(".", *c.Exclude)
+	} else if baseConfig != nil && baseConfig.Exclude != nil {
+		exclude = cleanTsConfigPatterns(baseConfigRel, baseConfig.Exclude)
+	}
+
 	config := TsConfig{
 		ConfigDir:            configDir,
 		ConfigName:           configName,
@@ -405,11 +523,99 @@ func parseTsConfigJSON(parsed map[string]*TsConfig, resolver TsConfigResolver, r
 		Jsx:                  jsx,
 		Types:                types,
 		References:           references,
+		Files:                files,
+		Include:              include,
+		Exclude:              exclude,
 	}
 
 	return &config, nil
 }
 
+func cleanTsConfigPatterns(rel string, patterns []string) []string {
+	cleaned := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		cleaned = append(cleaned, path.Join(rel, p))
+	}
+	return cleaned
+}
+
+// The default "exclude" when not specified, in addition to the outDir.
+var defaultTsConfigExclude = []string{"node_modules", "bower_components", "jspm_packages"}
+
+// HasCompilationSet returns true if the tsconfig restricts the compilation set
+// using "files", "include" or "exclude".
+func (c TsConfig) HasCompilationSet() bool {
+	return c.Files != nil || c.Include != nil || c.Exclude != nil
+}
+
+// IsFileIncluded returns true if a file, relative to the tsconfig ConfigDir, is part of the
+// compilation set determined by the tsconfig "files", "include" and "exclude".
+//
+// Patterns outside the tsconfig directory, such as those inherited from a tsconfig in a parent
+// directory, can not apply to files within the directory and are ignored. If no "files" or
+// "include" patterns apply within the directory all files are included.
+//
+// See https://www.typescriptlang.org/tsconfig/#include
+func (c TsConfig) IsFileIncluded(f string) bool {
+	files := localTsConfigPatterns(c.Files)
+	if slices.Contains(files, f) {
+		return true
+	}
+
+	include := localTsConfigPatterns(c.Include)
+	if len(include) == 0 {
+		// Only the "files" when "files" is specified without "include"
+		if len(files) > 0 {
+			return false
+		}
+		include = []string{"**/*"}
+	}
+
+	if !slices.ContainsFunc(include, func(pattern string) bool { return matchTsConfigPattern(pattern, f) }) {
+		return false
+	}
+
+	exclude := c.Exclude
+	if exclude == nil {
+		exclude = defaultTsConfigExclude
+		if c.OutDir != "." {
+			exclude = append(slices.Clone(exclude), c.OutDir)
+		}
+	}
+
+	return !slices.ContainsFunc(exclude, func(pattern string) bool { return matchTsConfigPattern(pattern, f) })
+}
+
+func localTsConfigPatterns(patterns []string) []string {
+	return slices.DeleteFunc(slices.Clone(patterns), func(p string) bool {
+		return p == ".." || strings.HasPrefix(p, "../")
+	})
+}
+
+// Match a tsconfig include/exclude pattern where patterns without wildcards or an
+// extension in the last segment are directories.
+func matchTsConfigPattern(pattern, f string) bool {
+	if pattern == "." {
+		return true
+	}
+
+	if !strings.ContainsAny(pattern, "*?") {
+		return f == pattern || strings.HasPrefix(f, pattern+"/")
+	}
+
+	if last := path.Base(pattern); !strings.ContainsAny(last, "*?") && path.Ext(last) == "" {
+		pattern = pattern + "/**/*"
+	}
+
+	glob, err := common.ParseGlobExpression(pattern)
+	if err != nil {
+		BazelLog.Warnf("Invalid tsconfig include/exclude pattern %q: %v", pattern, err)
+		return false
+	}
+
+	return glob(f)
+}
+
 func (c TsConfig) ToOutDir(f string) string {
 	return c.stripRootPrependDir(c.OutDir, f)
 }
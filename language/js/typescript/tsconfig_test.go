@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"path"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -121,7 +122,7 @@ func TestTsconfigLoad(t *testing.T) {
 		}
 		assertEqual(t, extender.Paths.Rel, "src", "should inherit Paths.Rel from extended")
 		assertEqual(t, extender.Paths.Map["alias-a"][0], "src/lib/a", "should inherit Paths.Rel from extended")
-		assertEqual(t, extender.Extends, "base.tsconfig.json", "should not fail extending")
+		assertEqual(t, strings.Join(extender.Extends, ","), "base.tsconfig.json", "should not fail extending")
 	})
 
 	t.Run("parse a tsconfig extending other in parent dir", func(t *testing.T) {
@@ -135,7 +136,7 @@ func TestTsconfigLoad(t *testing.T) {
 		}
 		assertEqual(t, extender.Paths.Rel, "../src", "should inherit Paths.Rel from extended")
 		assertEqual(t, extender.Paths.Map["alias-a"][0], "src/lib/a", "should inherit Paths.Rel from extended")
-		assertEqual(t, extender.Extends, "../base.tsconfig.json", "should not fail extending")
+		assertEqual(t, strings.Join(extender.Extends, ","), "../base.tsconfig.json", "should not fail extending")
 	})
 
 	t.Run("parse a tsconfig extending other in parent dir and overriding paths", func(t *testing.T) {
@@ -151,7 +152,7 @@ func TestTsconfigLoad(t *testing.T) {
 		_, aliasAExists := extender.Paths.Map["alias-a"]
 		assertTrue(t, !aliasAExists, "should override Paths from extended")
 		assertEqual(t, extender.Paths.Map["alias-b"][0], "src/lib/b", "should override Paths.Map")
-		assertEqual(t, extender.Extends, "../base.tsconfig.json", "should not fail extending")
+		assertEqual(t, strings.Join(extender.Extends, ","), "../base.tsconfig.json", "should not fail extending")
 	})
 
 	t.Run("parse a tsconfig file extending itself", func(t *testing.T) {
@@ -160,7 +161,7 @@ func TestTsconfigLoad(t *testing.T) {
 			t.Errorf("parseTsConfigJSONFile: %v", err)
 		}
 
-		assertEqual(t, recursive.Extends, "extends-recursive.json", "should not fail extending itself")
+		assertEqual(t, strings.Join(recursive.Extends, ","), "extends-recursive.json", "should not fail extending itself")
 	})
 
 	t.Run("parse a tsconfig file extending an unknown file", func(t *testing.T) {
@@ -169,7 +170,7 @@ func TestTsconfigLoad(t *testing.T) {
 			t.Errorf("parseTsConfigJSONFile: %v", err)
 		}
 
-		assertEqual(t, notFound.Extends, "does-not-exist.json", "should not fail extending unknown")
+		assertEqual(t, strings.Join(notFound.Extends, ","), "does-not-exist.json", "should not fail extending unknown")
 	})
 
 	t.Run("parse a tsconfig file extending a blank string", func(t *testing.T) {
@@ -178,7 +179,7 @@ func TestTsconfigLoad(t *testing.T) {
 			t.Errorf("parseTsConfigJSONFile: %v", err)
 		}
 
-		assertEqual(t, extendsBlank.Extends, "", "should not fail extending an empty str")
+		assertEqual(t, strings.Join(extendsBlank.Extends, ","), "", "should not fail extending an empty str")
 	})
 
 	t.Run("parse example tsconfig file with comments, trialing commas", func(t *testing.T) {
@@ -188,7 +189,33 @@ func TestTsconfigLoad(t *testing.T) {
 			t.Errorf("parseTsConfigJSONFile: %v", err)
 		}
 
-		assertEqual(t, unknown.Extends, ".svelte-kit/tsconfig.json", "should set Extends to blank when not found")
+		assertEqual(t, strings.Join(unknown.Extends, ","), ".svelte-kit/tsconfig.json", "should set Extends to blank when not found")
+	})
+
+	t.Run("parse a tsconfig extending multiple configs", func(t *testing.T) {
+		extender, err := parseTsConfigJSONFile(make(map[string]*TsConfig), identityResolver, ".", "tests/extends-multiple.json")
+		if err != nil {
+			t.Errorf("parseTsConfigJSONFile: %v", err)
+		}
+
+		if extender.ImportHelpers {
+			t.Errorf("should inherit compilerOptions.importHelpers from the last extended config")
+		}
+		assertEqual(t, string(extender.Jsx), "react-jsx", "should inherit jsx from the last extended config")
+		assertEqual(t, extender.Paths.Rel, "src", "should inherit Paths.Rel from the first extended config")
+		assertEqual(t, extender.Paths.Map["alias-a"][0], "src/lib/a", "should inherit Paths from the first extended config")
+		assertEqual(t, strings.Join(extender.Extends, ","), "base.tsconfig.json,jsx-base.json", "should record all extended configs")
+
+		reversed, err := parseTsConfigJSONFile(make(map[string]*TsConfig), identityResolver, ".", "tests/extends-multiple-reversed.json")
+		if err != nil {
+			t.Errorf("parseTsConfigJSONFile: %v", err)
+		}
+
+		if !reversed.ImportHelpers {
+			t.Errorf("should inherit compilerOptions.importHelpers from the last extended config")
+		}
+		assertEqual(t, string(reversed.Jsx), "react-jsx", "should inherit jsx from the first extended config")
+		assertEqual(t, reversed.OutDir, "dist", "should not inherit overridden options")
 	})
 
 	t.Run("parse a tsconfig file extending a named-import", func(t *testing.T) {
@@ -199,7 +226,7 @@ func TestTsconfigLoad(t *testing.T) {
 
 		assertEqual(t, extender.Paths.Rel, "src", "should inherit Paths.Rel from extended")
 		assertEqual(t, extender.Paths.Map["alias-a"][0], "src/lib/a", "should inherit Paths.Rel from extended")
-		assertEqual(t, extender.Extends, "foo", "should not fail extending")
+		assertEqual(t, strings.Join(extender.Extends, ","), "foo", "should not fail extending")
 	})
 }
 
@@ -616,3 +643,95 @@ func TestTsconfigOutRootDirs(t *testing.T) {
 		assertEqual(t, o1.ToDeclarationOutDir("src"), "src", "invalid rootdir prefix")
 	})
 }
+
+func TestTsconfigCompilationSet(t *testing.T) {
+	t.Run("no files, include or exclude", func(t *testing.T) {
+		c := parseTest(t, ".", `{}`)
+		if c.HasCompilationSet() {
+			t.Errorf("HasCompilationSet: expected false")
+		}
+		assertIncluded(t, c, "main.ts", true)
+		assertIncluded(t, c, "src/lib/a.ts", true)
+		assertIncluded(t, c, "node_modules/foo/index.ts", false)
+	})
+
+	t.Run("include directory", func(t *testing.T) {
+		c := parseTest(t, ".", `{"include": ["./src", "lib/"]}`)
+		if !c.HasCompilationSet() {
+			t.Errorf("HasCompilationSet: expected true")
+		}
+		assertIncluded(t, c, "main.ts", false)
+		assertIncluded(t, c, "src/a.ts", true)
+		assertIncluded(t, c, "src/lib/a.ts", true)
+		assertIncluded(t, c, "srcs/a.ts", false)
+		assertIncluded(t, c, "lib/a.ts", true)
+	})
+
+	t.Run("include patterns", func(t *testing.T) {
+		c := parseTest(t, ".", `{"include": ["src/**/*.ts", "*.tsx", "src/*/lib"]}`)
+		assertIncluded(t, c, "main.ts", false)
+		assertIncluded(t, c, "main.tsx", true)
+		assertIncluded(t, c, "src/a.ts", true)
+		assertIncluded(t, c, "src/a.tsx", false)
+		assertIncluded(t, c, "src/foo/lib/a.tsx", true)
+	})
+
+	t.Run("exclude patterns", func(t *testing.T) {
+		c := parseTest(t, ".", `{"exclude": ["**/*.spec.ts", "fixtures"], "compilerOptions": {"outDir": "dist"}}`)
+		assertIncluded(t, c, "main.ts", true)
+		assertIncluded(t, c, "main.spec.ts", false)
+		assertIncluded(t, c, "src/a.spec.ts", false)
+		assertIncluded(t, c, "fixtures/a.ts", false)
+		assertIncluded(t, c, "dist/a.ts", true)
+		assertIncluded(t, c, "node_modules/foo/index.ts", true)
+	})
+
+	t.Run("default exclude outDir", func(t *testing.T) {
+		c := parseTest(t, ".", `{"include": ["**/*"], "compilerOptions": {"outDir": "dist"}}`)
+		assertIncluded(t, c, "main.ts", true)
+		assertIncluded(t, c, "dist/a.ts", false)
+		assertIncluded(t, c, "node_modules/foo/index.ts", false)
+	})
+
+	t.Run("files", func(t *testing.T) {
+		c := parseTest(t, ".", `{"files": ["./main.ts", "other.ts"]}`)
+		assertIncluded(t, c, "main.ts", true)
+		assertIncluded(t, c, "other.ts", true)
+		assertIncluded(t, c, "lib.ts", false)
+
+		c2 := parseTest(t, ".", `{"files": ["main.ts"], "include": ["src"], "exclude": ["main.ts", "src/b.ts"]}`)
+		assertIncluded(t, c2, "main.ts", true)
+		assertIncluded(t, c2, "src/a.ts", true)
+		assertIncluded(t, c2, "src/b.ts", false)
+		assertIncluded(t, c2, "lib.ts", false)
+	})
+
+	t.Run("inherited files, include and exclude", func(t *testing.T) {
+		c, err := parseTsConfigJSONFile(make(map[string]*TsConfig), identityResolver, ".", "tests/extends-include-base.json")
+		if err != nil {
+			t.Fatalf("parseTsConfigJSONFile: %v", err)
+		}
+		assertIncluded(t, c, "main.ts", true)
+		assertIncluded(t, c, "other.ts", false)
+		assertIncluded(t, c, "src/a.ts", true)
+		assertIncluded(t, c, "src/a.spec.ts", false)
+		assertIncluded(t, c, "types/a.d.ts", true)
+	})
+
+	t.Run("inherited include outside the config directory", func(t *testing.T) {
+		c, err := parseTsConfigJSONFile(make(map[string]*TsConfig), identityResolver, ".", "tests/subdir/extends-include.json")
+		if err != nil {
+			t.Fatalf("parseTsConfigJSONFile: %v", err)
+		}
+		assertEqual(t, c.Include[0], "../src", "should rebase inherited include")
+		assertIncluded(t, c, "main.ts", true)
+	})
+}
+
+func assertIncluded(t *testing.T, c *TsConfig, f string, expected bool) {
+	t.Helper()
+
+	if actual := c.IsFileIncluded(f); actual != expected {
+		t.Errorf("IsFileIncluded(%q): expected %v, got %v", f, expected, actual)
+	}
+}
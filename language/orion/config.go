@@ -2,10 +2,18 @@ package gazelle
 
 import (
 	"iter"
+	"maps"
 
 	plugin "github.com/aspect-build/aspect-gazelle/language/orion/plugin"
 )
 
+// A directive to enable, disable or load plugins for a directory and its subdirectories:
+//
+//	# aspect:orion_plugin enable <plugin id>
+//	# aspect:orion_plugin disable <plugin id>
+//	# aspect:orion_plugin load <plugin file label>
+const Directive_OrionPlugin = "orion_plugin"
+
 type BUILDConfig struct {
 	// Shared across all
 	repoName string
@@ -20,6 +28,9 @@ type BUILDConfig struct {
 	// All directives of this BUILD
 	directiveRawValues map[string][]string
 
+	// Plugins enabled or disabled by directives of this BUILD or parents
+	pluginEnabled map[plugin.PluginId]bool
+
 	// Plugin specific config
 	pluginPrepareResults map[plugin.PluginId]pluginConfig
 }
@@ -31,6 +42,8 @@ func NewRootConfig(repoName string) *BUILDConfig {
 
 		directiveRawValues: make(map[string][]string),
 
+		pluginEnabled: make(map[string]bool),
+
 		pluginPrepareResults: make(map[string]pluginConfig),
 	}
 }
@@ -46,6 +59,9 @@ func (c *BUILDConfig) NewChildConfig(rel string) *BUILDConfig {
 	cCopy.parent = c
 	cCopy.directiveRawValues = make(map[string][]string)
 
+	// Inherited and may be modified by the child
+	cCopy.pluginEnabled = maps.Clone(c.pluginEnabled)
+
 	// Non-inherited that require cloning
 	// TODO: verify these should not be inherited
 	cCopy.pluginPrepareResults = make(map[string]pluginConfig)
@@ -62,11 +78,15 @@ func (p *BUILDConfig) appendDirectiveValue(key, value string) {
 	}
 }
 
-func (c *BUILDConfig) IsPluginEnabled(pluginId plugin.PluginId) bool {
-	if val, exists := c.getRawValue(string(pluginId), true); exists {
-		return val[len(val)-1] == "enabled"
+func (c *BUILDConfig) IsPluginEnabled(pluginId plugin.PluginId, isEnabledByDefault bool) bool {
+	if enabled, exists := c.pluginEnabled[pluginId]; exists {
+		return enabled
 	}
-	return true
+	return isEnabledByDefault
+}
+
+func (c *BUILDConfig) setPluginEnabled(pluginId plugin.PluginId, enabled bool) {
+	c.pluginEnabled[pluginId] = enabled
 }
 
 func (c *BUILDConfig) getRawValue(key string, inherit bool) ([]string, bool) {
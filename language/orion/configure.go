@@ -1,10 +1,19 @@
 package gazelle
 
 import (
+	"bytes"
 	"flag"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
 
+	common "github.com/aspect-build/aspect-gazelle/common"
+	"github.com/aspect-build/aspect-gazelle/common/bazel"
 	BazelLog "github.com/aspect-build/aspect-gazelle/common/logger"
 	"github.com/aspect-build/aspect-gazelle/language/orion/plugin"
 	"github.com/bazelbuild/bazel-gazelle/config"
@@ -16,7 +25,7 @@ var _ config.Configurer = (*GazelleHost)(nil)
 
 func (c *GazelleHost) KnownDirectives() []string {
 	if c.gazelleDirectives == nil {
-		c.gazelleDirectives = []string{}
+		c.gazelleDirectives = []string{Directive_OrionPlugin}
 
 		// TODO: verify no collisions with other plugins/globals
 
@@ -50,6 +59,13 @@ func (configurer *GazelleHost) Configure(c *config.Config, rel string, f *rule.F
 	if f != nil {
 		for _, d := range f.Directives {
 			config.appendDirectiveValue(d.Key, d.Value)
+
+			if d.Key == Directive_OrionPlugin {
+				configurer.configurePluginDirective(c, config, d.Value)
+			} else if _, isPlugin := configurer.plugins[d.Key]; isPlugin {
+				// A directive to enable/disable the plugin
+				config.setPluginEnabled(d.Key, d.Value == "enabled")
+			}
 		}
 	}
 
@@ -60,7 +76,7 @@ func (configurer *GazelleHost) Configure(c *config.Config, rel string, f *rule.F
 
 	// Prepare the plugins for this configuration.
 	for k, p := range configurer.plugins {
-		if !config.IsPluginEnabled(k) {
+		if !config.IsPluginEnabled(k, !configurer.directivePlugins[k]) {
 			continue
 		}
 
@@ -90,6 +106,31 @@ func (configurer *GazelleHost) Configure(c *config.Config, rel string, f *rule.F
 	}
 }
 
+func (configurer *GazelleHost) configurePluginDirective(c *config.Config, cfg *BUILDConfig, value string) {
+	action, arg, _ := strings.Cut(strings.TrimSpace(value), " ")
+	arg = strings.TrimSpace(arg)
+
+	switch action {
+	case "enable", "disable":
+		if _, exists := configurer.plugins[arg]; !exists {
+			common.MisconfiguredErrorf(c, "invalid value for directive %q: %s, unknown plugin %q", Directive_OrionPlugin, value, arg)
+			return
+		}
+		cfg.setPluginEnabled(arg, action == "enable")
+	case "load":
+		pluginIds, err := configurer.loadDirectivePlugin(c.RepoRoot, cfg.rel, arg)
+		if err != nil {
+			common.MisconfiguredErrorf(c, "invalid value for directive %q: %s: %v", Directive_OrionPlugin, value, err)
+			return
+		}
+		for _, pluginId := range pluginIds {
+			cfg.setPluginEnabled(pluginId, true)
+		}
+	default:
+		common.MisconfiguredErrorf(c, "invalid value for directive %q: %s, expected enable, disable or load", Directive_OrionPlugin, value)
+	}
+}
+
 func configToPrepareContext(p plugin.Plugin, cfg *BUILDConfig) plugin.PrepareContext {
 	ctx := plugin.PrepareContext{
 		RepoName:   cfg.repoName,
@@ -158,5 +199,94 @@ func (c *GazelleHost) RegisterFlags(fs *flag.FlagSet, cmd string, cfg *config.Co
 }
 
 func (c *GazelleHost) CheckFlags(fs *flag.FlagSet, cfg *config.Config) error {
+	c.preloadDirectivePlugins(cfg)
+	return nil
+}
+
+// Load the plugins of all `orion_plugin load` directives before gazelle reads the
+// known directives and rule kinds, registering the property directives, kinds and
+// loads of plugins only loaded by directives.
+//
+// Only the directories gazelle visits are searched: directories ignored by the
+// .bazelignore or `gazelle:exclude` directives and node_modules are skipped.
+//
+// Plugins failing to load are reported as misconfiguration errors, the same as when
+// the directive is configured.
+func (c *GazelleHost) preloadDirectivePlugins(cfg *config.Config) {
+	ignores, err := bazel.LoadBazelIgnore(cfg.RepoRoot)
+	if err != nil {
+		BazelLog.Warnf("Failed to load .bazelignore: %v", err)
+	}
+
+	c.preloadDirectoryPlugins(cfg, ignores, "", nil)
+}
+
+// Load the plugins of the directives in the directory rel and its subdirectories.
+//
+// The excludes are the `gazelle:exclude` patterns of the parent directories, relative
+// to the repository root.
+func (c *GazelleHost) preloadDirectoryPlugins(cfg *config.Config, ignores []string, rel string, excludes []common.GlobExpr) {
+	entries, err := os.ReadDir(filepath.Join(cfg.RepoRoot, rel))
+	if err != nil {
+		BazelLog.Warnf("Failed to find %q directives in %q: %v", Directive_OrionPlugin, rel, err)
+		return
+	}
+
+	if f := readPreloadBuildFile(cfg, rel, entries); f != nil {
+		for _, d := range f.Directives {
+			switch d.Key {
+			case "exclude":
+				expr, err := common.ParseGlobExpression(path.Join(rel, strings.TrimSpace(d.Value)))
+				if err != nil {
+					continue
+				}
+
+				// Excludes only apply to the directory and subdirectories
+				excludes = append(slices.Clip(excludes), expr)
+			case Directive_OrionPlugin:
+				if action, pluginLabel, _ := strings.Cut(strings.TrimSpace(d.Value), " "); action == "load" {
+					if _, err := c.loadDirectivePlugin(cfg.RepoRoot, rel, strings.TrimSpace(pluginLabel)); err != nil {
+						common.MisconfiguredErrorf(cfg, "invalid value for directive %q: %s: %v", Directive_OrionPlugin, d.Value, err)
+					}
+				}
+			}
+		}
+	}
+
+	for _, e := range entries {
+		if !e.IsDir() || e.Name() == ".git" || e.Name() == "node_modules" {
+			continue
+		}
+
+		subRel := path.Join(rel, e.Name())
+		if slices.Contains(ignores, subRel) || slices.ContainsFunc(excludes, func(exclude common.GlobExpr) bool { return exclude(subRel) }) {
+			continue
+		}
+
+		c.preloadDirectoryPlugins(cfg, ignores, subRel, excludes)
+	}
+}
+
+// Read the BUILD file of the directory if it may declare `orion_plugin` or
+// `gazelle:exclude` directives.
+func readPreloadBuildFile(cfg *config.Config, rel string, entries []fs.DirEntry) *rule.File {
+	for _, buildFileName := range cfg.ValidBuildFileNames {
+		i := slices.IndexFunc(entries, func(e fs.DirEntry) bool { return e.Name() == buildFileName && !e.IsDir() })
+		if i == -1 {
+			continue
+		}
+
+		p := filepath.Join(cfg.RepoRoot, rel, buildFileName)
+		content, err := os.ReadFile(p)
+		if err != nil || !(bytes.Contains(content, []byte(Directive_OrionPlugin)) || bytes.Contains(content, []byte("gazelle:exclude"))) {
+			return nil
+		}
+
+		f, err := rule.LoadData(p, rel, content)
+		if err != nil {
+			return nil
+		}
+		return f
+	}
 	return nil
 }
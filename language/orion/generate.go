@@ -49,6 +49,11 @@ func (host *GazelleHost) GenerateRules(args gazelleLanguage.GenerateArgs) gazell
 	return host.generateRules(cfg, args)
 }
 
+// All symbols have been added once all rules are generated.
+func (host *GazelleHost) DoneGeneratingRules() {
+	host.database.Freeze()
+}
+
 func (host *GazelleHost) generateRules(cfg *BUILDConfig, args gazelleLanguage.GenerateArgs) gazelleLanguage.GenerateResult {
 	queryCache := cache.Get(args.Config)
 
@@ -140,13 +145,14 @@ func (host *GazelleHost) generateRules(cfg *BUILDConfig, args gazelleLanguage.Ge
 			prep := prep
 			src := src
 			eg.Go(func() error {
-				actx := plugin.NewAnalyzeContext(prep.PrepareContext, &src, host.database)
-
-				err := host.plugins[pluginId].Analyze(actx)
+				symbols, err := host.analyzeSource(queryCache, args.Config.RepoRoot, path.Join(args.Rel, src.Path), pluginId, prep, &src)
 				if err != nil {
 					// TODO:
 					fmt.Println(fmt.Errorf("analyze failed for %s: %w", pluginId, err))
+					return nil
 				}
+
+				host.database.AddSymbols(symbols)
 				return nil
 			})
 		}
@@ -420,6 +426,58 @@ func (host *GazelleHost) runSourceQueries(queryCache cache.Cache, queries plugin
 	return qr, err
 }
 
+// Analyze a source file for the symbols it adds.
+//
+// The symbols are cached by the source file content and the plugin analyzing it,
+// so plugins loaded from files are only invoked for changed source files.
+func (host *GazelleHost) analyzeSource(queryCache cache.Cache, baseDir, f string, pluginId plugin.PluginId, prep pluginConfig, src *plugin.TargetSource) (plugin.TargetSymbols, error) {
+	analyze := func() (plugin.TargetSymbols, error) {
+		symbols := plugin.TargetSymbols{}
+		actx := plugin.NewAnalyzeContext(prep.PrepareContext, src, &symbols)
+		if err := host.plugins[pluginId].Analyze(actx); err != nil {
+			return nil, err
+		}
+		return symbols, nil
+	}
+
+	pluginDigest, isCacheable := host.pluginDigests[pluginId]
+	if !isCacheable {
+		return analyze()
+	}
+
+	analyzeHash := computeAnalyzeCacheKey(pluginId, pluginDigest, prep, src.Path)
+
+	r, _, err := queryCache.LoadOrStoreFile(baseDir, f, analyzeHash, func(p string, sourceCode []byte) (any, error) {
+		return analyze()
+	})
+
+	var symbols plugin.TargetSymbols
+	if r != nil {
+		symbols = r.(plugin.TargetSymbols)
+	}
+
+	return symbols, err
+}
+
+func computeAnalyzeCacheKey(pluginId plugin.PluginId, pluginDigest string, prep pluginConfig, f string) string {
+	cacheDigest := crypto.MD5.New()
+
+	// The analysis inputs other than the source file content: the plugin, the
+	// prepare context and the queries determining the query results.
+	fmt.Fprintf(cacheDigest, "%s\x00%s\x00%s\x00%s\x00", pluginId, pluginDigest, prep.RepoName, prep.Rel)
+	for name, value := range prep.Properties.All() {
+		fmt.Fprintf(cacheDigest, "%s=%#v\x00", name, value)
+	}
+
+	queries := make(plugin.NamedQueries)
+	for queryId, query := range prep.getQueriesForFile(f) {
+		queries[queryId] = query
+	}
+	cacheDigest.Write([]byte(computeQueriesCacheKey(queries)))
+
+	return "orion.analyze|" + hex.EncodeToString(cacheDigest.Sum(nil))
+}
+
 func (host *GazelleHost) runSourceCodeQueries(queries plugin.NamedQueries, sourceCode []byte, f string) (plugin.QueryResults, error) {
 	// Split queries by type to invoke in batches
 	queriesByType := make(map[plugin.QueryType]plugin.NamedQueries)
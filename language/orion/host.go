@@ -6,12 +6,15 @@ package gazelle
  */
 
 import (
+	"crypto/md5"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path"
 	"path/filepath"
 	"slices"
 	"strings"
+	"sync"
 
 	"github.com/aspect-build/aspect-gazelle/common/bazel/workspace"
 	BazelLog "github.com/aspect-build/aspect-gazelle/common/logger"
@@ -30,11 +33,24 @@ const GazelleLanguageName = "orion"
 type GazelleHost struct {
 	database *plugin.Database
 
+	// The content digest of the file of each plugin loaded from a file
+	pluginDigests map[plugin.PluginId]string
+
 	// Hosted plugins
-	// TODO: support enabling/disabling/adding in subdirs
 	pluginIds []plugin.PluginId
 	plugins   map[plugin.PluginId]plugin.Plugin
 
+	// Plugins loaded by directives, only enabled where loaded and in subdirectories
+	directivePlugins     map[plugin.PluginId]bool
+	directivePluginFiles map[string][]plugin.PluginId
+
+	// Symbol providers each import provider may additionally resolve to
+	providerConsumes map[string][]string
+
+	// Symbol providers of the indexed targets
+	indexedProviders      map[string]bool
+	indexedProvidersMutex sync.Mutex
+
 	// Metadata about rules being generated. May be pre-configured, potentially loaded from *.star etc
 	kinds           map[string]plugin.RuleKind
 	sourceRuleKinds *treeset.Set
@@ -47,14 +63,20 @@ type GazelleHost struct {
 
 var _ gazelleLanguage.Language = (*GazelleHost)(nil)
 var _ gazelleLanguage.ModuleAwareLanguage = (*GazelleHost)(nil)
+var _ gazelleLanguage.FinishableLanguage = (*GazelleHost)(nil)
 var _ plugin.PluginHost = (*GazelleHost)(nil)
 
 func NewLanguage(plugins ...string) gazelleLanguage.Language {
 	l := &GazelleHost{
-		plugins:         make(map[string]plugin.Plugin),
-		kinds:           make(map[string]plugin.RuleKind),
-		sourceRuleKinds: treeset.NewWithStringComparator(),
-		database:        &plugin.Database{},
+		plugins:              make(map[string]plugin.Plugin),
+		pluginDigests:        make(map[string]string),
+		directivePlugins:     make(map[string]bool),
+		directivePluginFiles: make(map[string][]plugin.PluginId),
+		providerConsumes:     make(map[string][]string),
+		indexedProviders:     make(map[string]bool),
+		kinds:                make(map[string]plugin.RuleKind),
+		sourceRuleKinds:      treeset.NewWithStringComparator(),
+		database:             plugin.NewDatabase(),
 	}
 
 	// Initialize with builtin kinds. Plugins can add/overwrite these.
@@ -154,6 +176,10 @@ func (h *GazelleHost) loadEnvStarzellePlugins() {
 		h.LoadPlugin(builtinPluginDir, p)
 	}
 }
+
+// Load a plugin of the host such as the builtin and env plugins. Plugins failing to
+// load are printed and skipped, unlike plugins loaded by `orion_plugin` directives
+// which are reported as misconfiguration errors.
 func (h *GazelleHost) LoadPlugin(pluginDir, pluginPath string) {
 	// Can not add new plugins after configuration/data-collection has started
 	if h.gazelleKindInfo != nil || h.gazelleLoadInfo != nil {
@@ -161,7 +187,52 @@ func (h *GazelleHost) LoadPlugin(pluginDir, pluginPath string) {
 		return
 	}
 
-	err := starzelle.LoadProxy(h, pluginDir, pluginPath)
+	if err := h.loadPlugin(pluginDir, pluginPath); err != nil {
+		fmt.Printf("%v\n", err)
+	}
+}
+
+// Load a plugin declared by a directive of the BUILD file in the directory rel,
+// returning the ids of the plugins declared by the plugin file.
+//
+// Plugins loaded by directives are only enabled for the directory and subdirectories,
+// deferring their Prepare to those directories.
+func (h *GazelleHost) loadDirectivePlugin(repoRoot, rel, pluginLabel string) ([]plugin.PluginId, error) {
+	l, err := label.Parse(pluginLabel)
+	if err != nil {
+		return nil, fmt.Errorf("invalid orion plugin label %q: %w", pluginLabel, err)
+	}
+	if l.Repo != "" {
+		return nil, fmt.Errorf("orion plugin %q: plugins of external repositories are not supported", pluginLabel)
+	}
+
+	l = l.Abs("", rel)
+	pluginPath := path.Join(l.Pkg, l.Name)
+
+	// Plugins loaded by multiple directives are loaded once
+	if pluginIds, loaded := h.directivePluginFiles[pluginPath]; loaded {
+		return pluginIds, nil
+	}
+
+	loadedPlugins := len(h.pluginIds)
+
+	if err := h.loadPlugin(repoRoot, pluginPath); err != nil {
+		return nil, err
+	}
+
+	pluginIds := slices.Clone(h.pluginIds[loadedPlugins:])
+	for _, pluginId := range pluginIds {
+		h.directivePlugins[pluginId] = true
+	}
+	h.directivePluginFiles[pluginPath] = pluginIds
+
+	return pluginIds, nil
+}
+
+func (h *GazelleHost) loadPlugin(pluginDir, pluginPath string) error {
+	loadedPlugins := len(h.pluginIds)
+
+	loadedFiles, err := starzelle.LoadProxy(h, pluginDir, pluginPath)
 	if err != nil {
 		BazelLog.Infof("Failed to load orion plugin %v\n", err)
 
@@ -170,9 +241,34 @@ func (h *GazelleHost) LoadPlugin(pluginDir, pluginPath string) {
 		// when run in tests.
 		errStr := strings.ReplaceAll(err.Error(), pluginDir+"/", "")
 
-		fmt.Printf("Failed to load orion plugin %v\n", errStr)
-		return
+		return fmt.Errorf("Failed to load orion plugin %v", errStr)
 	}
+
+	// Plugin results may be cached as long as the plugin file and the files it
+	// load()s, including files of external repositories, are unchanged.
+	pluginFile := pluginPath
+	if !path.IsAbs(pluginFile) {
+		pluginFile = path.Join(pluginDir, pluginPath)
+	}
+
+	digest := md5.New()
+	for _, f := range append([]string{pluginFile}, loadedFiles...) {
+		content, err := os.ReadFile(f)
+		if err != nil {
+			BazelLog.Warnf("Failed to read orion plugin file %q: %v", f, err)
+			return nil
+		}
+
+		fmt.Fprintf(digest, "%d\x00", len(content))
+		digest.Write(content)
+	}
+
+	pluginDigest := hex.EncodeToString(digest.Sum(nil))
+	for _, pluginId := range h.pluginIds[loadedPlugins:] {
+		h.pluginDigests[pluginId] = pluginDigest
+	}
+
+	return nil
 }
 
 func (h *GazelleHost) AddPlugin(plugin plugin.Plugin) {
@@ -185,11 +281,27 @@ func (h *GazelleHost) AddPlugin(plugin plugin.Plugin) {
 	h.plugins[plugin.Name()] = plugin
 }
 
+func (h *GazelleHost) AddProviderCompatibility(provider string, consumes []string) {
+	BazelLog.Infof("Provider %q consumes: %v", provider, consumes)
+
+	for _, consumed := range consumes {
+		if consumed != provider && !slices.Contains(h.providerConsumes[provider], consumed) {
+			h.providerConsumes[provider] = append(h.providerConsumes[provider], consumed)
+		}
+	}
+}
+
 func (h *GazelleHost) AddKind(k plugin.RuleKind) {
 	if _, exists := h.kinds[k.Name]; exists {
 		BazelLog.Errorf("Duplicate rule kind %q", k.Name)
 	}
 
+	// Plugins of directives are preloaded before gazelle reads the kinds, kinds may
+	// only be unknown for directives not found while preloading.
+	if h.gazelleKindInfo != nil || h.gazelleLoadInfo != nil {
+		BazelLog.Warnf("Rule kind %q added after configuration has started is unknown to gazelle", k.Name)
+	}
+
 	BazelLog.Infof("Kind added: %q", k.Name)
 	h.kinds[k.Name] = k
 
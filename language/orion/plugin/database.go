@@ -1,21 +1,191 @@
 package plugin
 
-import "sync"
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	BazelLog "github.com/aspect-build/aspect-gazelle/common/logger"
+)
 
 // TODO: move to its own package
 
+// Collects the symbols added by plugins.
+type SymbolCollector interface {
+	AddSymbol(label Label, symbol Symbol)
+}
+
+// A list of symbols, such as the symbols added while analyzing a single source.
+//
+// Not safe for concurrent use.
+type TargetSymbols []TargetSymbol
+
+var _ SymbolCollector = (*TargetSymbols)(nil)
+
+func (s *TargetSymbols) AddSymbol(label Label, symbol Symbol) {
+	*s = append(*s, TargetSymbol{
+		Symbol: symbol,
+		Label:  label,
+	})
+}
+
+// The symbols added by plugins indexed by provider and id.
+//
+// Symbols are added concurrently while generating rules, the database is then
+// frozen and read without locking while resolving imports.
 type Database struct {
-	Symbols []TargetSymbol
+	// The labels of each symbol, sorted once frozen
+	symbols map[Symbol][]Label
+
+	// The ids of each provider, sorted once frozen
+	providerIds map[string][]string
 
-	symbolMutex sync.Mutex
+	frozen      atomic.Bool
+	symbolMutex sync.RWMutex
+}
+
+var _ SymbolCollector = (*Database)(nil)
+
+func NewDatabase() *Database {
+	return &Database{
+		symbols:     make(map[Symbol][]Label),
+		providerIds: make(map[string][]string),
+	}
 }
 
 func (d *Database) AddSymbol(label Label, symbol Symbol) {
+	d.AddSymbols(TargetSymbols{{Symbol: symbol, Label: label}})
+}
+
+func (d *Database) AddSymbols(symbols TargetSymbols) {
+	if len(symbols) == 0 {
+		return
+	}
+
+	if d.frozen.Load() {
+		BazelLog.Fatalf("Cannot add symbol %q after the symbol database is frozen", symbols[0].Id)
+		return
+	}
+
 	d.symbolMutex.Lock()
 	defer d.symbolMutex.Unlock()
 
-	d.Symbols = append(d.Symbols, TargetSymbol{
-		Symbol: symbol,
-		Label:  label,
-	})
+	for _, s := range symbols {
+		labels, exists := d.symbols[s.Symbol]
+		if !exists {
+			d.providerIds[s.Provider] = append(d.providerIds[s.Provider], s.Id)
+		}
+		if !slices.Contains(labels, s.Label) {
+			d.symbols[s.Symbol] = append(labels, s.Label)
+		}
+	}
+}
+
+// Freeze the database once all symbols have been added, subsequent reads are
+// lock-free.
+func (d *Database) Freeze() {
+	d.symbolMutex.Lock()
+	defer d.symbolMutex.Unlock()
+
+	if d.frozen.Load() {
+		return
+	}
+
+	for _, ids := range d.providerIds {
+		slices.Sort(ids)
+	}
+
+	// Labels are added concurrently, sort for a deterministic order
+	for _, labels := range d.symbols {
+		slices.SortFunc(labels, compareLabels)
+	}
+
+	d.frozen.Store(true)
+}
+
+func (d *Database) readLock() func() {
+	if d.frozen.Load() {
+		return func() {}
+	}
+
+	d.symbolMutex.RLock()
+	return d.symbolMutex.RUnlock
+}
+
+// Find the labels of the symbol of the provider with the id, sorted once the
+// database is frozen.
+//
+// Ids ending with "*" are wildcards matching all ids with the prefix, such as
+// star imports of all symbols within a package.
+func (d *Database) Find(provider, id string) []Label {
+	defer d.readLock()()
+
+	prefix, isWildcard := strings.CutSuffix(id, "*")
+	if !isWildcard {
+		return slices.Clone(d.symbols[Symbol{Id: id, Provider: provider}])
+	}
+
+	labels := []Label{}
+	for _, matchId := range d.findPrefix(provider, prefix) {
+		for _, l := range d.symbols[Symbol{Id: matchId, Provider: provider}] {
+			if !slices.Contains(labels, l) {
+				labels = append(labels, l)
+			}
+		}
+	}
+	slices.SortFunc(labels, compareLabels)
+	return labels
+}
+
+func compareLabels(a, b Label) int {
+	return cmp.Or(
+		strings.Compare(a.Repo, b.Repo),
+		strings.Compare(a.Pkg, b.Pkg),
+		strings.Compare(a.Name, b.Name),
+	)
+}
+
+// The ids of the provider starting with the prefix.
+func (d *Database) findPrefix(provider, prefix string) []string {
+	ids := d.providerIds[provider]
+
+	if !d.frozen.Load() {
+		matches := []string{}
+		for _, id := range ids {
+			if strings.HasPrefix(id, prefix) {
+				matches = append(matches, id)
+			}
+		}
+		return matches
+	}
+
+	// Binary search the sorted ids for the range of ids with the prefix
+	start, _ := slices.BinarySearch(ids, prefix)
+	end := start
+	for end < len(ids) && strings.HasPrefix(ids[end], prefix) {
+		end++
+	}
+	return ids[start:end]
+}
+
+// Find the symbols with the id across all providers, sorted by provider.
+func (d *Database) FindById(id string) []TargetSymbol {
+	defer d.readLock()()
+
+	providers := make([]string, 0, len(d.providerIds))
+	for provider := range d.providerIds {
+		providers = append(providers, provider)
+	}
+	slices.Sort(providers)
+
+	symbols := []TargetSymbol{}
+	for _, provider := range providers {
+		symbol := Symbol{Id: id, Provider: provider}
+		for _, l := range d.symbols[symbol] {
+			symbols = append(symbols, TargetSymbol{Symbol: symbol, Label: l})
+		}
+	}
+	return symbols
 }
@@ -2,6 +2,8 @@ package plugin
 
 import (
 	"encoding/gob"
+	"iter"
+	"maps"
 	"slices"
 	"strings"
 
@@ -13,6 +15,9 @@ type PluginId = string
 type PluginHost interface {
 	AddKind(k RuleKind)
 	AddPlugin(plugin Plugin)
+
+	// Declare imports of the provider may resolve to symbols of the consumed providers
+	AddProviderCompatibility(provider string, consumes []string)
 }
 
 // TODO: change the interface into a factory method (at least in starzelle)
@@ -25,6 +30,10 @@ type Plugin interface {
 	Prepare(ctx PrepareContext) PrepareResult
 	Analyze(ctx AnalyzeContext) error
 	DeclareTargets(ctx DeclareTargetsContext) DeclareTargetsResult
+
+	// Resolve imports not matching exactly one target, nil to fallback
+	// to the default resolution.
+	Resolve(ctx ResolveContext) *ResolveResult
 }
 
 type PropertyType = string
@@ -89,6 +98,17 @@ func (pv *PropertyValues) Add(name string, value interface{}) {
 	pv.values[name] = value
 }
 
+// The property values sorted by name.
+func (pv PropertyValues) All() iter.Seq2[string, interface{}] {
+	return func(yield func(string, interface{}) bool) {
+		for _, name := range slices.Sorted(maps.Keys(pv.values)) {
+			if !yield(name, pv.values[name]) {
+				return
+			}
+		}
+	}
+}
+
 // The context for an extension to prepare for generating targets.
 type PrepareContext struct {
 	RepoName   string
@@ -182,19 +202,19 @@ type Label struct {
 
 type AnalyzeContext struct {
 	PrepareContext
-	Source   *TargetSource
-	database *Database
+	Source  *TargetSource
+	symbols SymbolCollector
 }
 
 func (a AnalyzeContext) AddSymbol(label Label, symbol Symbol) {
-	a.database.AddSymbol(label, symbol)
+	a.symbols.AddSymbol(label, symbol)
 }
 
-func NewAnalyzeContext(prep PrepareContext, source *TargetSource, database *Database) AnalyzeContext {
+func NewAnalyzeContext(prep PrepareContext, source *TargetSource, symbols SymbolCollector) AnalyzeContext {
 	return AnalyzeContext{
 		PrepareContext: prep,
 		Source:         source,
-		database:       database,
+		symbols:        symbols,
 	}
 }
 
@@ -262,6 +282,28 @@ type DeclareTargetsResult struct {
 	Actions []TargetAction
 }
 
+// The context for an extension to resolve an import of a target matching
+// multiple or no targets.
+type ResolveContext struct {
+	// The import being resolved
+	Import TargetImport
+
+	// The targets providing the imported symbol, excluding the importing target
+	Candidates []Label
+
+	// The target importing the symbol
+	From Label
+}
+
+// The result of an extension resolving an import.
+type ResolveResult struct {
+	// The targets the import resolves to, none if the import requires no dependency
+	Labels []Label
+
+	// If the import is provided natively such as a language builtin
+	Native bool
+}
+
 type TargetSource struct {
 	Path         string
 	QueryResults QueryResults
@@ -274,4 +316,5 @@ func init() {
 	gob.Register(QueryMatch{})
 	gob.Register(QueryCapture{})
 	gob.Register(QueryProcessorResult{})
+	gob.Register(TargetSymbols{})
 }
@@ -518,6 +518,76 @@ func addSymbol(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tupl
 	return starlark.None, nil
 }
 
+// ---------------- ResolveContext
+
+var _ starlark.Value = (*ResolveContext)(nil)
+var _ starlark.HasAttrs = (*ResolveContext)(nil)
+
+func (ctx ResolveContext) Attr(name string) (starlark.Value, error) {
+	switch name {
+	case "imp":
+		return ctx.Import, nil
+	case "candidates":
+		candidates := make([]starlark.Value, 0, len(ctx.Candidates))
+		for _, c := range ctx.Candidates {
+			candidates = append(candidates, c)
+		}
+		return starlark.NewList(candidates), nil
+	case "from":
+		return ctx.From, nil
+	default:
+		return nil, starlark.NoSuchAttrError(name)
+	}
+}
+
+func (ctx ResolveContext) AttrNames() []string {
+	return []string{"imp", "candidates", "from"}
+}
+
+func (ctx ResolveContext) String() string {
+	return fmt.Sprintf("ResolveContext{imp: %v, candidates: %v, from: %v}", ctx.Import, ctx.Candidates, ctx.From)
+}
+func (ctx ResolveContext) Type() string         { return "ResolveContext" }
+func (ctx ResolveContext) Freeze()              {}
+func (ctx ResolveContext) Truth() starlark.Bool { return starlark.True }
+func (ctx ResolveContext) Hash() (uint32, error) {
+	return 0, fmt.Errorf("unhashable: %s", ctx.Type())
+}
+
+// ---------------- ResolveResult
+
+var _ starlark.Value = (*ResolveResult)(nil)
+var _ starlark.HasAttrs = (*ResolveResult)(nil)
+
+func (r ResolveResult) Attr(name string) (starlark.Value, error) {
+	switch name {
+	case "labels":
+		labels := make([]starlark.Value, 0, len(r.Labels))
+		for _, l := range r.Labels {
+			labels = append(labels, l)
+		}
+		return starlark.NewList(labels), nil
+	case "native":
+		return starlark.Bool(r.Native), nil
+	default:
+		return nil, starlark.NoSuchAttrError(name)
+	}
+}
+
+func (r ResolveResult) AttrNames() []string {
+	return []string{"labels", "native"}
+}
+
+func (r ResolveResult) String() string {
+	return fmt.Sprintf("ResolveResult{labels: %v, native: %v}", r.Labels, r.Native)
+}
+func (r ResolveResult) Type() string         { return "ResolveResult" }
+func (r ResolveResult) Freeze()              {}
+func (r ResolveResult) Truth() starlark.Bool { return starlark.True }
+func (r ResolveResult) Hash() (uint32, error) {
+	return 0, fmt.Errorf("unhashable: %s", r.Type())
+}
+
 // ---------------- Gazelle Label
 
 var _ starlark.Value = (*Label)(nil)
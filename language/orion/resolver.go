@@ -3,7 +3,9 @@ package gazelle
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"path"
+	"slices"
 	"strings"
 
 	common "github.com/aspect-build/aspect-gazelle/common"
@@ -68,7 +70,7 @@ func (re *GazelleHost) Imports(c *config.Config, r *rule.Rule, f *rule.File) []r
 	// This rule was generated by this gazelle extension.
 	if declaration := r.PrivateAttr(targetDeclarationKey); declaration != nil {
 		BazelLog.Debugf("Imports(%s): //%s:%s (generated %s)", GazelleLanguageName, f.Pkg, r.Name(), r.Kind())
-		return symbolToImportSpecList(declaration.(plugin.TargetDeclaration).Symbols)
+		return re.symbolToImportSpecList(declaration.(plugin.TargetDeclaration).Symbols)
 	}
 
 	cfg := getBUILDConfig(c, f.Pkg)
@@ -100,7 +102,7 @@ func (re *GazelleHost) Imports(c *config.Config, r *rule.Rule, f *rule.File) []r
 			// the result would be different.
 			if declaration := g.PrivateAttr(targetDeclarationKey); declaration != nil {
 				BazelLog.Debugf("Imports(%s): //%s:%s (not generated %s)", GazelleLanguageName, f.Pkg, g.Name(), g.Kind())
-				return symbolToImportSpecList(declaration.(plugin.TargetDeclaration).Symbols)
+				return re.symbolToImportSpecList(declaration.(plugin.TargetDeclaration).Symbols)
 			}
 			break
 		}
@@ -109,10 +111,14 @@ func (re *GazelleHost) Imports(c *config.Config, r *rule.Rule, f *rule.File) []r
 	return nil
 }
 
-func symbolToImportSpecList(symbols []plugin.Symbol) []resolve.ImportSpec {
+func (re *GazelleHost) symbolToImportSpecList(symbols []plugin.Symbol) []resolve.ImportSpec {
+	re.indexedProvidersMutex.Lock()
+	defer re.indexedProvidersMutex.Unlock()
+
 	res := make([]resolve.ImportSpec, 0, len(symbols))
 	for _, s := range symbols {
 		res = append(res, symbolToImportSpec(s))
+		re.indexedProviders[s.Provider] = true
 	}
 	return res
 }
@@ -188,7 +194,7 @@ func (re *GazelleHost) resolveImports(
 	errs := []error{}
 
 	for _, imp := range imports {
-		resolutionType, dep, err := re.resolveImport(c, ix, pluginId, imp, from)
+		resolutionType, resolved, err := re.resolveImport(c, ix, pluginId, imp, from)
 		if err != nil {
 			return nil, err
 		}
@@ -204,6 +210,24 @@ func (re *GazelleHost) resolveImports(
 					imp.Id, imp.Provider, imp.From, pluginId,
 				)
 
+				// Symbols of the same id the import is not compatible with
+				if incompatible := re.findIncompatibleSymbols(c, ix, imp); len(incompatible) > 0 {
+					incompatibleList := make([]string, 0, len(incompatible))
+					incompatibleProviders := make([]string, 0, len(incompatible))
+					for _, symbol := range incompatible {
+						incompatibleList = append(incompatibleList, fmt.Sprintf("%s (provider %q)", toGazelleLabel(symbol.Label), symbol.Provider))
+						if quoted := fmt.Sprintf("%q", symbol.Provider); !slices.Contains(incompatibleProviders, quoted) {
+							incompatibleProviders = append(incompatibleProviders, quoted)
+						}
+					}
+
+					notFound = fmt.Errorf(
+						"%w\t2. Declare the compatibility with the providers of the incompatible symbols %s:\n"+
+							"\t\taspect.provider_compatibility(provider = %q, consumes = [%s])\n",
+						notFound, strings.Join(incompatibleList, ", "), imp.Provider, strings.Join(incompatibleProviders, ", "),
+					)
+				}
+
 				errs = append(errs, notFound)
 				continue
 			}
@@ -213,8 +237,8 @@ func (re *GazelleHost) resolveImports(
 			continue
 		}
 
-		if dep != nil {
-			deps.Add(dep)
+		for _, dep := range resolved {
+			deps.Add(&dep)
 		}
 	}
 
@@ -227,30 +251,40 @@ func (host *GazelleHost) resolveImport(
 	pluginId plugin.PluginId,
 	impt plugin.TargetImport,
 	from label.Label,
-) (ResolutionType, *label.Label, error) {
+) (ResolutionType, []label.Label, error) {
 	// Convert to gazelle resolve.ImportSpec api
 	importSpec := symbolToImportSpec(impt.Symbol)
 
 	// Gazelle overrides
 	// TODO: generalize into gazelle/common
 	if override, ok := resolve.FindRuleWithOverride(c, importSpec, GazelleLanguageName); ok {
-		return Resolution_Label, &override, nil
+		return Resolution_Label, []label.Label{override}, nil
 	}
 
+	// The providers of symbols the import may resolve to
+	providers := host.importProviders(impt.Provider)
+
 	// Match imports generated by the starzelle gazelle plugin.
 	// TODO: generalize into gazelle/common
-	if matches := ix.FindRulesByImportWithConfig(c, importSpec, GazelleLanguageName); len(matches) > 0 {
+	matches := []resolve.FindResult{}
+	for _, provider := range providers {
+		matches = append(matches, ix.FindRulesByImportWithConfig(c, resolve.ImportSpec{Lang: provider, Imp: impt.Id}, GazelleLanguageName)...)
+	}
+	if len(matches) > 0 {
 		filteredMatches := make([]label.Label, 0, len(matches))
 		for _, match := range matches {
-			// Prevent from adding itself as a dependency.
-			if !match.IsSelfImport(from) {
+			// Prevent from adding itself as a dependency, or the same target providing multiple providers.
+			if !match.IsSelfImport(from) && !slices.ContainsFunc(filteredMatches, match.Label.Equal) {
 				filteredMatches = append(filteredMatches, match.Label)
 			}
 		}
 
-		// Too many results, don't know which is correct
-		// TODO: resolution conflicts must be solved by plugins
+		// Too many results, the plugin may know which are correct
 		if len(filteredMatches) > 1 {
+			if resolutionType, resolved := host.resolvePluginImport(pluginId, impt, filteredMatches, from); resolutionType != Resolution_NotFound {
+				return resolutionType, resolved, nil
+			}
+
 			return Resolution_Error, nil, fmt.Errorf(
 				"Import %q from %q (%s) resolved to multiple targets (%s) - this must be fixed using the \"aspect:resolve\" directive",
 				impt.Id, impt.From, pluginId, targetListFromResults(matches))
@@ -261,9 +295,7 @@ func (host *GazelleHost) resolveImport(
 			return Resolution_None, nil, nil
 		}
 
-		match := filteredMatches[0]
-
-		return Resolution_Label, &match, nil
+		return Resolution_Label, filteredMatches, nil
 	}
 
 	// TODO: "native" imports
@@ -271,22 +303,121 @@ func (host *GazelleHost) resolveImport(
 	// 	return Resolution_Native, nil, nil
 	// }
 
-	// Lookup symbols across plugins in the symbol db
-	for _, symbol := range host.database.Symbols {
-		// TODO: only match correct "providers"
+	// Lookup symbols of compatible providers across plugins in the symbol db
+	for _, provider := range providers {
+		symbolLabels := host.database.Find(provider, impt.Id)
+		if len(symbolLabels) == 0 {
+			continue
+		}
+
+		// Wildcard imports depend on all matching symbols, otherwise the first symbol by label
+		if !strings.HasSuffix(impt.Id, "*") {
+			symbolLabels = symbolLabels[:1]
+		}
 
-		if importSpec.Imp == symbol.Symbol.Id {
-			l := label.Label{
-				Repo:     symbol.Label.Repo,
-				Pkg:      symbol.Label.Pkg,
-				Name:     symbol.Label.Name,
-				Relative: false,
-			}
-			return Resolution_Label, &l, nil
+		resolved := make([]label.Label, 0, len(symbolLabels))
+		for _, l := range symbolLabels {
+			resolved = append(resolved, toGazelleLabel(l))
 		}
+		return Resolution_Label, resolved, nil
 	}
 
-	return Resolution_NotFound, nil, nil
+	// Not found, the plugin may know where the import is from
+	resolutionType, resolved := host.resolvePluginImport(pluginId, impt, nil, from)
+	return resolutionType, resolved, nil
+}
+
+// The providers of symbols imports of the provider may resolve to.
+func (host *GazelleHost) importProviders(provider string) []string {
+	return append([]string{provider}, host.providerConsumes[provider]...)
+}
+
+// Find symbols of the imported id from providers incompatible with the import.
+func (host *GazelleHost) findIncompatibleSymbols(c *config.Config, ix *resolve.RuleIndex, impt plugin.TargetImport) []plugin.TargetSymbol {
+	providers := host.importProviders(impt.Provider)
+
+	incompatible := []plugin.TargetSymbol{}
+	for _, symbol := range host.database.FindById(impt.Id) {
+		if !slices.Contains(providers, symbol.Provider) {
+			incompatible = append(incompatible, symbol)
+		}
+	}
+
+	host.indexedProvidersMutex.Lock()
+	indexedProviders := slices.Sorted(maps.Keys(host.indexedProviders))
+	host.indexedProvidersMutex.Unlock()
+
+	for _, provider := range indexedProviders {
+		if slices.Contains(providers, provider) {
+			continue
+		}
+
+		for _, match := range ix.FindRulesByImportWithConfig(c, resolve.ImportSpec{Lang: provider, Imp: impt.Id}, GazelleLanguageName) {
+			incompatible = append(incompatible, plugin.TargetSymbol{
+				Symbol: plugin.Symbol{Id: impt.Id, Provider: provider},
+				Label:  toPluginLabel(match.Label),
+			})
+		}
+	}
+
+	return incompatible
+}
+
+// Resolve an import using the resolve callback of the plugin declaring the importing
+// target. Returns Resolution_NotFound if the plugin leaves the import unresolved.
+func (host *GazelleHost) resolvePluginImport(pluginId plugin.PluginId, impt plugin.TargetImport, candidates []label.Label, from label.Label) (ResolutionType, []label.Label) {
+	p, found := host.plugins[pluginId]
+	if !found {
+		return Resolution_NotFound, nil
+	}
+
+	ctx := plugin.ResolveContext{
+		Import:     impt,
+		Candidates: make([]plugin.Label, 0, len(candidates)),
+		From:       toPluginLabel(from),
+	}
+	for _, candidate := range candidates {
+		ctx.Candidates = append(ctx.Candidates, toPluginLabel(candidate))
+	}
+
+	result := p.Resolve(ctx)
+	if result == nil {
+		return Resolution_NotFound, nil
+	}
+
+	BazelLog.Debugf("import %q for target %v resolved by plugin %q: %v", impt.Id, from, pluginId, result)
+
+	if result.Native {
+		return Resolution_Native, nil
+	}
+
+	// Resolved to no targets, no dependency is needed
+	if len(result.Labels) == 0 {
+		return Resolution_None, nil
+	}
+
+	resolved := make([]label.Label, 0, len(result.Labels))
+	for _, l := range result.Labels {
+		resolved = append(resolved, toGazelleLabel(l))
+	}
+	return Resolution_Label, resolved
+}
+
+func toGazelleLabel(l plugin.Label) label.Label {
+	return label.Label{
+		Repo:     l.Repo,
+		Pkg:      l.Pkg,
+		Name:     l.Name,
+		Relative: false,
+	}
+}
+
+func toPluginLabel(l label.Label) plugin.Label {
+	return plugin.Label{
+		Repo: l.Repo,
+		Pkg:  l.Pkg,
+		Name: l.Name,
+	}
 }
 
 var _ resolve.CrossResolver = (*GazelleHost)(nil)
@@ -3,6 +3,7 @@ package starlark
 import (
 	"fmt"
 	"path"
+	"slices"
 
 	"github.com/bazelbuild/bazel-gazelle/label"
 	"go.starlark.net/lib/json"
@@ -57,19 +58,46 @@ func makeLoadOptions(opts *syntax.FileOptions, predeclared starlark.StringDict)
 }
 
 // Wrap a `moduleLoader` and add support for load()ing similar to bazel rulesets.
+//
+// Labels of external repositories are resolved via the MODULE.bazel of the rootDir,
+// labels without a repository are within the repository of the file load()ing them.
 func createRepoLoader(rootDir string, loader moduleLoader) moduleLoader {
+	// Lazy loaded on the first load() from an external repository
+	var repos *repoResolver
+	var reposErr error
+
+	// The repository directory of each file loaded from an external repository
+	moduleRepoDirs := make(map[string]string)
+
 	return func(thread *starlark.Thread, module string) (starlark.StringDict, error) {
 		moduleLabel, err := label.Parse(module)
 		if err != nil {
 			return nil, fmt.Errorf("invalid load() label: %s", module)
 		}
 
+		repoDir := rootDir
 		if moduleLabel.Repo != "" {
-			// FUTURE: loading from external repositories, local repository by name.
-			return nil, fmt.Errorf("repository load() unsupported: %s", module)
+			if repos == nil && reposErr == nil {
+				repos, reposErr = newRepoResolver(rootDir)
+			}
+			if reposErr != nil {
+				return nil, fmt.Errorf("repository load() %s: %w", module, reposErr)
+			}
+
+			repoDir, err = repos.repoDir(moduleLabel.Repo)
+			if err != nil {
+				return nil, fmt.Errorf("repository load() %s: %w", module, err)
+			}
+		} else if thread.CallStackDepth() > 0 {
+			if loadingRepoDir, isExternal := moduleRepoDirs[thread.CallFrame(0).Pos.Filename()]; isExternal {
+				repoDir = loadingRepoDir
+			}
 		}
 
-		modulePath := path.Join(rootDir, moduleLabel.Pkg, moduleLabel.Name)
+		modulePath := path.Join(repoDir, moduleLabel.Pkg, moduleLabel.Name)
+		if repoDir != rootDir {
+			moduleRepoDirs[modulePath] = repoDir
+		}
 
 		return loader(thread, modulePath)
 	}
@@ -80,7 +108,9 @@ func threadPrint(t *starlark.Thread, msg string) {
 	fmt.Printf("%s: %s\n", t.Name, msg)
 }
 
-func Eval(rootDir, starpath string, libs starlark.StringDict, locals map[string]interface{}) (starlark.StringDict, error) {
+// Evaluate the starlark file, returning its globals and the paths of all files
+// load()ed while evaluating it in the order first loaded.
+func Eval(rootDir, starpath string, libs starlark.StringDict, locals map[string]interface{}) (starlark.StringDict, []string, error) {
 	// Predeclared libs in addition to the go.starlark.net/starlark standard library:
 	// * https://github.com/google/starlark-go/blob/f86470692795f8abcf9f837a3c53cf031c5a3d7e/starlark/library.go#L36-L73
 	// * https://github.com/google/starlark-go/blob/f86470692795f8abcf9f837a3c53cf031c5a3d7e/cmd/starlark/starlark.go#L96-L100
@@ -93,8 +123,15 @@ func Eval(rootDir, starpath string, libs starlark.StringDict, locals map[string]
 		predeclared[libName] = lib
 	}
 
-	loader := makeLoadOptions(opts, predeclared)
-	loader = createRepoLoader(rootDir, loader)
+	// The files load()ed directly or transitively, all loads use the thread loader
+	loadedFiles := []string{}
+	fileLoader := makeLoadOptions(opts, predeclared)
+	loader := createRepoLoader(rootDir, func(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+		if !slices.Contains(loadedFiles, module) {
+			loadedFiles = append(loadedFiles, module)
+		}
+		return fileLoader(thread, module)
+	})
 
 	thread := starlark.Thread{
 		Name:  "AspectConfigure",
@@ -105,5 +142,6 @@ func Eval(rootDir, starpath string, libs starlark.StringDict, locals map[string]
 		thread.SetLocal(localName, local)
 	}
 
-	return starlark.ExecFileOptions(opts, &thread, path.Join(rootDir, starpath), nil, predeclared)
+	globals, err := starlark.ExecFileOptions(opts, &thread, path.Join(rootDir, starpath), nil, predeclared)
+	return globals, loadedFiles, err
 }
@@ -3,6 +3,8 @@ package starlark
 import (
 	"os"
 	"path"
+	"slices"
+	"strings"
 	"testing"
 
 	"go.starlark.net/starlark"
@@ -27,7 +29,8 @@ func run(t *testing.T, code string) (starlark.StringDict, error) {
 		t.Errorf("Temp star close failure: %v", err)
 	}
 
-	return Eval(testDir, testFile, make(map[string]starlark.Value), make(map[string]interface{}))
+	globals, _, err := Eval(testDir, testFile, make(map[string]starlark.Value), make(map[string]interface{}))
+	return globals, err
 }
 
 func runOk(t *testing.T, code string) starlark.StringDict {
@@ -80,3 +83,157 @@ y = "s"
 		}
 	})
 }
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for p, content := range files {
+		if err := os.MkdirAll(path.Dir(path.Join(dir, p)), 0755); err != nil {
+			t.Fatalf("Temp dir create failure: %v", err)
+		}
+		if err := os.WriteFile(path.Join(dir, p), []byte(content), 0644); err != nil {
+			t.Fatalf("Temp file write failure: %v", err)
+		}
+	}
+}
+
+func TestStarlarkLoadRepository(t *testing.T) {
+	t.Run("local_path_override", func(t *testing.T) {
+		rootDir := t.TempDir()
+		writeFiles(t, rootDir, map[string]string{
+			"MODULE.bazel": `
+bazel_dep(name = "my_plugins", version = "1.0.0", repo_name = "plugins")
+local_path_override(module_name = "my_plugins", path = "vendor/my_plugins")
+`,
+			"vendor/my_plugins/gazelle/common.axl": `
+load("//gazelle:utils.axl", "y")
+x = y + 1
+`,
+			"vendor/my_plugins/gazelle/utils.axl": "y = 1",
+			"test.star": `
+load("@plugins//gazelle:common.axl", "x")
+z = x
+`,
+		})
+
+		res, loaded, err := Eval(rootDir, "test.star", make(map[string]starlark.Value), make(map[string]interface{}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if z, _ := starlark.AsInt32(res["z"]); z != 2 {
+			t.Errorf("Expected z = 2, got %v", res["z"])
+		}
+
+		expectedLoaded := []string{
+			path.Join(rootDir, "vendor/my_plugins/gazelle/common.axl"),
+			path.Join(rootDir, "vendor/my_plugins/gazelle/utils.axl"),
+		}
+		if !slices.Equal(loaded, expectedLoaded) {
+			t.Errorf("Expected loaded files %v, got %v", expectedLoaded, loaded)
+		}
+	})
+
+	t.Run("output base", func(t *testing.T) {
+		rootDir := t.TempDir()
+		outputBase := t.TempDir()
+		writeFiles(t, outputBase, map[string]string{
+			"execroot/_main/bazel-out/.keep":          "",
+			"external/my_plugins+/gazelle/common.axl": "x = 1",
+		})
+		writeFiles(t, rootDir, map[string]string{
+			"MODULE.bazel": `bazel_dep(name = "my_plugins", version = "1.0.0")`,
+			"test.star":    "load(\"@my_plugins//gazelle:common.axl\", \"x\")\nz = x",
+		})
+		if err := os.Symlink(path.Join(outputBase, "execroot/_main/bazel-out"), path.Join(rootDir, "bazel-out")); err != nil {
+			t.Fatal(err)
+		}
+
+		res, _, err := Eval(rootDir, "test.star", make(map[string]starlark.Value), make(map[string]interface{}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if z, _ := starlark.AsInt32(res["z"]); z != 1 {
+			t.Errorf("Expected z = 1, got %v", res["z"])
+		}
+	})
+
+	t.Run("output base env", func(t *testing.T) {
+		rootDir := t.TempDir()
+		outputBase := t.TempDir()
+		writeFiles(t, outputBase, map[string]string{
+			"external/my_plugins+/gazelle/common.axl": "x = 1",
+		})
+		writeFiles(t, rootDir, map[string]string{
+			"MODULE.bazel": `bazel_dep(name = "my_plugins", version = "1.0.0")`,
+			"test.star":    "load(\"@my_plugins//gazelle:common.axl\", \"x\")\nz = x",
+		})
+		t.Setenv(outputBaseEnv, outputBase)
+
+		res, _, err := Eval(rootDir, "test.star", make(map[string]starlark.Value), make(map[string]interface{}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if z, _ := starlark.AsInt32(res["z"]); z != 1 {
+			t.Errorf("Expected z = 1, got %v", res["z"])
+		}
+	})
+
+	t.Run("no output base", func(t *testing.T) {
+		rootDir := t.TempDir()
+		writeFiles(t, rootDir, map[string]string{
+			"MODULE.bazel": `bazel_dep(name = "my_plugins", version = "1.0.0")`,
+			"test.star":    `load("@my_plugins//gazelle:common.axl", "x")`,
+		})
+		t.Setenv(outputBaseEnv, "")
+
+		_, _, err := Eval(rootDir, "test.star", make(map[string]starlark.Value), make(map[string]interface{}))
+		if err == nil || !strings.Contains(err.Error(), outputBaseEnv) {
+			t.Errorf("Expected output base error referencing %s, got %v", outputBaseEnv, err)
+		}
+	})
+
+	t.Run("not fetched", func(t *testing.T) {
+		rootDir := t.TempDir()
+		outputBase := t.TempDir()
+		writeFiles(t, rootDir, map[string]string{
+			"MODULE.bazel": `bazel_dep(name = "my_plugins", version = "1.0.0")`,
+			"test.star":    `load("@my_plugins//gazelle:common.axl", "x")`,
+		})
+		t.Setenv(outputBaseEnv, outputBase)
+
+		_, _, err := Eval(rootDir, "test.star", make(map[string]starlark.Value), make(map[string]interface{}))
+		if err == nil || !strings.Contains(err.Error(), path.Join(outputBase, "external/my_plugins+")) {
+			t.Errorf("Expected not found error with the path tried, got %v", err)
+		}
+	})
+
+	t.Run("module extension repository", func(t *testing.T) {
+		rootDir := t.TempDir()
+		writeFiles(t, rootDir, map[string]string{
+			"MODULE.bazel": `
+ext = use_extension("//:ext.bzl", "ext")
+use_repo(ext, "generated", other = "generated_other")
+`,
+			"test.star": `load("@generated//:common.axl", "x")`,
+		})
+
+		_, _, err := Eval(rootDir, "test.star", make(map[string]starlark.Value), make(map[string]interface{}))
+		if err == nil || !strings.Contains(err.Error(), `repository "generated" of a module extension is not supported`) {
+			t.Errorf("Expected module extension error, got %v", err)
+		}
+	})
+
+	t.Run("unknown repository", func(t *testing.T) {
+		rootDir := t.TempDir()
+		writeFiles(t, rootDir, map[string]string{
+			"MODULE.bazel": `bazel_dep(name = "my_plugins", version = "1.0.0")`,
+			"test.star":    `load("@other//gazelle:common.axl", "x")`,
+		})
+
+		_, _, err := Eval(rootDir, "test.star", make(map[string]starlark.Value), make(map[string]interface{}))
+		if err == nil || !strings.Contains(err.Error(), `unknown repository "other"`) {
+			t.Errorf("Expected unknown repository error, got %v", err)
+		}
+	})
+}
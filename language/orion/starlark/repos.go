@@ -0,0 +1,194 @@
+package starlark
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	bzl "github.com/bazelbuild/buildtools/build"
+)
+
+// External repositories load()ed from are resolved using the bazel_dep() and
+// local_path_override() declarations of the root MODULE.bazel.
+//
+// Repositories with a local_path_override() are read from the override path,
+// others from the external/ directory of the Bazel output base. Repositories of
+// module extensions imported via use_repo() are not supported.
+
+const moduleFileName = "MODULE.bazel"
+
+// The env var overriding the Bazel output base.
+const outputBaseEnv = "ORION_OUTPUT_BASE"
+
+// The bzlmod canonical repository directory name suffixes of Bazel 8+ and Bazel 7.
+var canonicalRepoSuffixes = []string{"+", "~"}
+
+type repoResolver struct {
+	rootDir string
+
+	// The name and apparent name of the root module
+	moduleName, moduleRepoName string
+
+	// The module name of each apparent repository name
+	apparentRepos map[string]string
+
+	// The local path override of each module
+	localOverrides map[string]string
+
+	// The repositories of module extensions imported via use_repo()
+	extensionRepos map[string]bool
+
+	// The lazily computed Bazel output base
+	outputBaseDir string
+}
+
+func newRepoResolver(rootDir string) (*repoResolver, error) {
+	modulePath := path.Join(rootDir, moduleFileName)
+	content, err := os.ReadFile(modulePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", moduleFileName, err)
+	}
+
+	f, err := bzl.ParseModule(modulePath, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", moduleFileName, err)
+	}
+
+	r := &repoResolver{
+		rootDir:        rootDir,
+		apparentRepos:  make(map[string]string),
+		localOverrides: make(map[string]string),
+		extensionRepos: make(map[string]bool),
+	}
+
+	for _, stmt := range f.Stmt {
+		call, isCall := stmt.(*bzl.CallExpr)
+		if !isCall {
+			continue
+		}
+
+		fn, isIdent := call.X.(*bzl.Ident)
+		if !isIdent {
+			continue
+		}
+
+		switch fn.Name {
+		case "module":
+			r.moduleName = callStringArg(call, "name")
+			r.moduleRepoName = callStringArg(call, "repo_name")
+		case "bazel_dep":
+			name := callStringArg(call, "name")
+			if name == "" {
+				continue
+			}
+
+			repoName := callStringArg(call, "repo_name")
+			if repoName == "" {
+				repoName = name
+			}
+			r.apparentRepos[repoName] = name
+		case "local_path_override":
+			if moduleName := callStringArg(call, "module_name"); moduleName != "" {
+				r.localOverrides[moduleName] = callStringArg(call, "path")
+			}
+		case "use_repo":
+			for _, arg := range call.List[min(1, len(call.List)):] {
+				switch arg := arg.(type) {
+				case *bzl.StringExpr:
+					r.extensionRepos[arg.Value] = true
+				case *bzl.AssignExpr:
+					if key, isIdent := arg.LHS.(*bzl.Ident); isIdent {
+						r.extensionRepos[key.Name] = true
+					}
+				}
+			}
+		}
+	}
+
+	return r, nil
+}
+
+// The string value of a keyword argument of a call, or "" if not a string.
+func callStringArg(call *bzl.CallExpr, name string) string {
+	for _, arg := range call.List {
+		assign, isAssign := arg.(*bzl.AssignExpr)
+		if !isAssign {
+			continue
+		}
+
+		if key, isIdent := assign.LHS.(*bzl.Ident); isIdent && key.Name == name {
+			if value, isString := assign.RHS.(*bzl.StringExpr); isString {
+				return value.Value
+			}
+		}
+	}
+	return ""
+}
+
+// The directory of the repository with the apparent name.
+func (r *repoResolver) repoDir(repoName string) (string, error) {
+	if repoName == r.moduleName || repoName == r.moduleRepoName {
+		return r.rootDir, nil
+	}
+
+	moduleName, found := r.apparentRepos[repoName]
+	if !found {
+		if r.extensionRepos[repoName] {
+			return "", fmt.Errorf("repository %q of a module extension is not supported, only bazel_dep() repositories of %s", repoName, moduleFileName)
+		}
+		return "", fmt.Errorf("unknown repository %q, not a bazel_dep() of %s", repoName, moduleFileName)
+	}
+
+	if override, hasOverride := r.localOverrides[moduleName]; hasOverride {
+		if !path.IsAbs(override) {
+			override = path.Join(r.rootDir, override)
+		}
+		if !isDir(override) {
+			return "", fmt.Errorf("repository %q not found at the local_path_override() path %q", repoName, override)
+		}
+		return override, nil
+	}
+
+	outputBase, err := r.outputBase()
+	if err != nil {
+		return "", fmt.Errorf("repository %q: %w", repoName, err)
+	}
+
+	tried := make([]string, 0, len(canonicalRepoSuffixes))
+	for _, suffix := range canonicalRepoSuffixes {
+		dir := path.Join(outputBase, "external", moduleName+suffix)
+		if isDir(dir) {
+			return dir, nil
+		}
+		tried = append(tried, dir)
+	}
+
+	return "", fmt.Errorf("repository %q not found at %s, the repository must be fetched by bazel", repoName, strings.Join(tried, " or "))
+}
+
+// The Bazel output base, from the ORION_OUTPUT_BASE env var or the bazel-out
+// convenience symlink pointing to <output_base>/execroot/<workspace>/bazel-out.
+//
+// `bazel info output_base` is not run as it may start, or block on, the Bazel server.
+func (r *repoResolver) outputBase() (string, error) {
+	if r.outputBaseDir != "" {
+		return r.outputBaseDir, nil
+	}
+
+	if outputBase := os.Getenv(outputBaseEnv); outputBase != "" {
+		r.outputBaseDir = outputBase
+	} else if bazelOut, err := filepath.EvalSymlinks(path.Join(r.rootDir, "bazel-out")); err == nil {
+		r.outputBaseDir = filepath.Dir(filepath.Dir(filepath.Dir(bazelOut)))
+	} else {
+		return "", fmt.Errorf("failed to find the bazel output base, no bazel-out symlink in %q: set %s to the `bazel info output_base` directory", r.rootDir, outputBaseEnv)
+	}
+
+	return r.outputBaseDir, nil
+}
+
+func isDir(p string) bool {
+	info, err := os.Stat(p)
+	return err == nil && info.IsDir()
+}
@@ -29,7 +29,8 @@ type starzelleState struct {
 	host       plugin.PluginHost
 }
 
-func LoadProxy(host plugin.PluginHost, pluginDir, pluginPath string) error {
+// Load the plugins of the plugin file, returning the paths of the files load()ed by it.
+func LoadProxy(host plugin.PluginHost, pluginDir, pluginPath string) ([]string, error) {
 	BazelLog.Infof("Evaluate orion plugin: %q", pluginPath)
 
 	state := starzelleState{
@@ -43,12 +44,12 @@ func LoadProxy(host plugin.PluginHost, pluginDir, pluginPath string) error {
 		"aspect": aspectModule,
 	}
 
-	_, err := starEval.Eval(pluginDir, pluginPath, libs, evalState)
+	_, loadedFiles, err := starEval.Eval(pluginDir, pluginPath, libs, evalState)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return loadedFiles, nil
 }
 
 func (s *starzelleState) addKind(_ *starlark.Thread, name starlark.String, attributes *starlark.Dict) error {
@@ -60,7 +61,7 @@ func (s *starzelleState) addKind(_ *starlark.Thread, name starlark.String, attri
 	return nil
 }
 
-func (s *starzelleState) addPlugin(t *starlark.Thread, pluginId starlark.String, properties *starlark.Dict, prepare, analyze, declare *starlark.Function) error {
+func (s *starzelleState) addPlugin(t *starlark.Thread, pluginId starlark.String, properties *starlark.Dict, prepare, analyze, declare, resolve *starlark.Function) error {
 	var pluginProperties map[string]plugin.Property
 	var err error
 
@@ -86,6 +87,7 @@ func (s *starzelleState) addPlugin(t *starlark.Thread, pluginId starlark.String,
 		prepare:    prepare,
 		analyze:    analyze,
 		declare:    declare,
+		resolve:    resolve,
 	})
 
 	return nil
@@ -101,6 +103,9 @@ type starzellePluginProxy struct {
 	properties                map[string]plugin.Property
 	prepare, analyze, declare *starlark.Function
 
+	// Optional resolution of imports not matching exactly one target
+	resolve *starlark.Function
+
 	// The thread this plugin is running in.
 	t *starlark.Thread
 }
@@ -180,6 +185,61 @@ func (p starzellePluginProxy) DeclareTargets(ctx plugin.DeclareTargetsContext) p
 	}
 }
 
+func (p starzellePluginProxy) Resolve(ctx plugin.ResolveContext) *plugin.ResolveResult {
+	if p.resolve == nil {
+		return nil
+	}
+
+	v, err := starlark.Call(p.t, p.resolve, starlark.Tuple{ctx}, starUtils.EmptyKwArgs)
+	if err != nil {
+		errStr := starUtils.ErrorStr(fmt.Sprintf("Failed to invoke %s:Resolve()", p.name), err)
+		BazelLog.Error(errStr)
+		fmt.Print(errStr)
+		return nil
+	}
+
+	BazelLog.Debugf("%s:resolve(%q): %v\n", p.name, ctx.Import.Id, v)
+
+	result, err := readResolveResult(v)
+	if err != nil {
+		errStr := fmt.Sprintf("Resolve %v of %q is not a ResolveResult: %v\n", v, ctx.Import.Id, err)
+		BazelLog.Error(errStr)
+		fmt.Print(errStr)
+		return nil
+	}
+
+	return result
+}
+
+// Read the value returned by a resolve callback: None to fallback to the default
+// resolution, a Label, a list of Labels or a ResolveResult.
+func readResolveResult(v starlark.Value) (*plugin.ResolveResult, error) {
+	switch v := v.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case plugin.ResolveResult:
+		return &v, nil
+	case plugin.Label:
+		return &plugin.ResolveResult{Labels: []plugin.Label{v}}, nil
+	case *starlark.List:
+		labels, err := starUtils.ReadList(v, readLabel)
+		if err != nil {
+			return nil, err
+		}
+		return &plugin.ResolveResult{Labels: labels}, nil
+	}
+
+	return nil, fmt.Errorf("unexpected type %s", v.Type())
+}
+
+func readLabel(v starlark.Value) (plugin.Label, error) {
+	l, isLabel := v.(plugin.Label)
+	if !isLabel {
+		return plugin.Label{}, fmt.Errorf("%v (%s) is not a Label", v, v.Type())
+	}
+	return l, nil
+}
+
 func readRuleKind(n starlark.String, v starlark.Value) (plugin.RuleKind, error) {
 	from, err1 := starUtils.ReadMapEntry(v, "From", starUtils.ReadString, "")
 	matchAny, err2 := starUtils.ReadMapEntry(v, "MatchAny", starUtils.ReadBool, false)
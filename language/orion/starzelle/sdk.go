@@ -8,6 +8,7 @@ package starzelle
 
 import (
 	"fmt"
+	"slices"
 
 	common "github.com/aspect-build/aspect-gazelle/common"
 	"github.com/aspect-build/aspect-gazelle/language/orion/plugin"
@@ -23,7 +24,7 @@ func deprecatedRegisterConfigureExtension(t *starlark.Thread, b *starlark.Builti
 func registerOrionPlugin(t *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var pluginId starlark.String
 	var properties *starlark.Dict
-	var prepare, analyze, declare *starlark.Function
+	var prepare, analyze, declare, resolve *starlark.Function
 
 	err := starlark.UnpackArgs(
 		"orion_extension",
@@ -34,6 +35,7 @@ func registerOrionPlugin(t *starlark.Thread, b *starlark.Builtin, args starlark.
 		"prepare?", &prepare,
 		"analyze?", &analyze,
 		"declare?", &declare,
+		"resolve?", &resolve,
 	)
 	if err != nil {
 		return nil, err
@@ -46,6 +48,7 @@ func registerOrionPlugin(t *starlark.Thread, b *starlark.Builtin, args starlark.
 		prepare,
 		analyze,
 		declare,
+		resolve,
 	)
 
 	return starlark.None, err
@@ -76,6 +79,34 @@ func registerGazelleRuleKind(t *starlark.Thread, b *starlark.Builtin, args starl
 	return starlark.None, err
 }
 
+func registerProviderCompatibility(t *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var provider starlark.String
+	var consumesValue *starlark.List
+
+	err := starlark.UnpackArgs(
+		"provider_compatibility",
+		args,
+		kwargs,
+		"provider", &provider,
+		"consumes", &consumesValue,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	consumes, err := starUtils.ReadStringList(consumesValue)
+	if err != nil {
+		return nil, err
+	}
+
+	if provider.GoString() == "" || slices.Contains(consumes, "") {
+		return nil, fmt.Errorf("providers cannot be empty")
+	}
+
+	t.Local(proxyStateKey).(*starzelleState).host.AddProviderCompatibility(provider.GoString(), consumes)
+	return starlark.None, nil
+}
+
 func readQueryFilters(v starlark.Value) ([]string, common.GlobExpr, error) {
 	if v == nil {
 		return nil, nil, nil
@@ -425,6 +456,39 @@ func newLabel(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwar
 	}, nil
 }
 
+func newResolveResult(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var labelsValue *starlark.List
+	var native starlark.Bool
+
+	err := starlark.UnpackArgs(
+		"ResolveResult",
+		args,
+		kwargs,
+		"labels?", &labelsValue,
+		"native?", &native,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []plugin.Label
+	if labelsValue != nil {
+		labels, err = starUtils.ReadList(labelsValue, readLabel)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if native && len(labels) > 0 {
+		return nil, fmt.Errorf("native imports cannot resolve to labels")
+	}
+
+	return plugin.ResolveResult{
+		Labels: labels,
+		Native: bool(native),
+	}, nil
+}
+
 func newProperty(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var propType starlark.String
 	var propDefault starlark.Value = starlark.None
@@ -458,12 +522,14 @@ var aspectModule = starUtils.CreateModule(
 		"register_rule_kind":           deprecatedRegisterRuleKind,
 		"orion_extension":              registerOrionPlugin,
 		"gazelle_rule_kind":            registerGazelleRuleKind,
+		"provider_compatibility":       registerProviderCompatibility,
 		"AstQuery":                     newAstQuery,
 		"RegexQuery":                   newRegexQuery,
 		"RawQuery":                     newRawQuery,
 		"JsonQuery":                    newJsonQuery,
 		"YamlQuery":                    newYamlQuery,
 		"PrepareResult":                newPrepareResult,
+		"ResolveResult":                newResolveResult,
 		"Import":                       newImport,
 		"Symbol":                       newSymbol,
 		"Label":                        newLabel,